@@ -0,0 +1,184 @@
+// Command yamagiconf-docgen generates a Go file registering the doc comments
+// of the fields of a configuration type and all struct types of the same
+// package it references using yamagiconf.RegisterFieldDocs.
+// JSONSchema, Template and Docs use the registered documentation for
+// fields without a `doc` struct tag.
+//
+// Usage with go generate:
+//
+//	//go:generate go run github.com/romshark/yamagiconf/cmd/yamagiconf-docgen -type Config
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	fType := flag.String("type", "", "name of the configuration struct type (required)")
+	fDir := flag.String("dir", ".", "directory of the package")
+	fOutput := flag.String("output", "",
+		"output file name (default: <dir>/<type>_docs_gen.go)")
+	flag.Parse()
+
+	if *fType == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	output := *fOutput
+	if output == "" {
+		output = filepath.Join(*fDir, strings.ToLower(*fType)+"_docs_gen.go")
+	}
+	src, err := generate(*fDir, *fType, filepath.Base(output))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "yamagiconf-docgen: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(output, src, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "yamagiconf-docgen: writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+var errTypeNotFound = errors.New("struct type not found")
+
+// generate returns the source of the Go file registering the field docs
+// of struct type typeName in the package in dir.
+// File outputName is excluded from parsing.
+// If none of the fields are documented, the file only contains the
+// package clause.
+func generate(dir, typeName, outputName string) ([]byte, error) {
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("importing package: %w", err)
+	}
+	fset := token.NewFileSet()
+	structs := map[string]*ast.StructType{}
+	for _, name := range pkg.GoFiles {
+		if name == outputName {
+			continue
+		}
+		f, err := parser.ParseFile(
+			fset, filepath.Join(dir, name), nil, parser.ParseComments,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("parsing: %w", err)
+		}
+		for _, decl := range f.Decls {
+			d, ok := decl.(*ast.GenDecl)
+			if !ok || d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				s := spec.(*ast.TypeSpec)
+				if st, ok := s.Type.(*ast.StructType); ok && !s.Assign.IsValid() &&
+					s.TypeParams == nil {
+					structs[s.Name.Name] = st
+				}
+			}
+		}
+	}
+	if _, ok := structs[typeName]; !ok {
+		return nil, fmt.Errorf("%w: %s", errTypeNotFound, typeName)
+	}
+
+	g := &generator{structs: structs, visited: map[string]bool{}}
+	g.visit(typeName)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by yamagiconf-docgen -type %s. "+
+		"DO NOT EDIT.\n\n", typeName)
+	fmt.Fprintf(&b, "package %s\n", pkg.Name)
+	if len(g.types) < 1 {
+		// Without any field docs the import would be unused.
+		return format.Source(b.Bytes())
+	}
+	b.WriteString("\nimport \"github.com/romshark/yamagiconf\"\n\n")
+	b.WriteString("func init() {\n")
+	for _, t := range g.types {
+		fmt.Fprintf(&b, "\tyamagiconf.RegisterFieldDocs[%s](map[string]string{\n",
+			t.name)
+		for _, f := range t.fields {
+			fmt.Fprintf(&b, "\t\t%s: %s,\n",
+				strconv.Quote(f.name), strconv.Quote(f.doc))
+		}
+		b.WriteString("\t})\n")
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
+
+type generator struct {
+	structs map[string]*ast.StructType
+	visited map[string]bool
+	types   []typeDocs
+}
+
+type typeDocs struct {
+	name   string
+	fields []fieldDoc
+}
+
+type fieldDoc struct{ name, doc string }
+
+// visit collects the field docs of struct type name and all struct types
+// of the package referenced by its fields.
+func (g *generator) visit(name string) {
+	st, ok := g.structs[name]
+	if !ok || g.visited[name] {
+		return
+	}
+	g.visited[name] = true
+	t := typeDocs{name: name}
+	var referenced []string
+	for _, f := range st.Fields.List {
+		referenced = appendReferencedTypes(referenced, f.Type)
+		doc := strings.TrimSpace(f.Doc.Text())
+		if doc == "" {
+			doc = strings.TrimSpace(f.Comment.Text())
+		}
+		if doc == "" {
+			continue
+		}
+		for _, n := range f.Names {
+			t.fields = append(t.fields, fieldDoc{name: n.Name, doc: doc})
+		}
+	}
+	if len(t.fields) > 0 {
+		g.types = append(g.types, t)
+	}
+	for _, r := range referenced {
+		g.visit(r)
+	}
+}
+
+// appendReferencedTypes appends the names of the types of the same package
+// referenced by type expression x to names.
+func appendReferencedTypes(names []string, x ast.Expr) []string {
+	switch x := x.(type) {
+	case *ast.Ident:
+		return append(names, x.Name)
+	case *ast.StarExpr:
+		return appendReferencedTypes(names, x.X)
+	case *ast.ArrayType:
+		return appendReferencedTypes(names, x.Elt)
+	case *ast.MapType:
+		names = appendReferencedTypes(names, x.Key)
+		return appendReferencedTypes(names, x.Value)
+	case *ast.IndexExpr:
+		// Type arguments like in yamagiconf.Secret[Credentials].
+		return appendReferencedTypes(names, x.Index)
+	}
+	return names
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	src, err := generate("testdata/config", "Config", "config_docs_gen.go")
+	require.NoError(t, err)
+	require.Equal(t, `// Code generated by yamagiconf-docgen -type Config. DO NOT EDIT.
+
+package config
+
+import "github.com/romshark/yamagiconf"
+
+func init() {
+	yamagiconf.RegisterFieldDocs[Config](map[string]string{
+		"Name":    "Name is the name of the service.",
+		"Timeout": "Timeout limits the duration of requests.\nZero means no timeout.",
+		"Debug":   "Debug enables debug logging.",
+	})
+	yamagiconf.RegisterFieldDocs[Server](map[string]string{
+		"Host": "Host is the host name of the server.",
+	})
+	yamagiconf.RegisterFieldDocs[Credentials](map[string]string{
+		"User": "User is the name of the user.",
+	})
+	yamagiconf.RegisterFieldDocs[Embedded](map[string]string{
+		"Region": "Region is the deployment region.",
+	})
+}
+`, string(src))
+}
+
+func TestGenerateNoDocs(t *testing.T) {
+	src, err := generate("testdata/config", "Undocumented", "config_docs_gen.go")
+	require.NoError(t, err)
+	require.Equal(t, `// Code generated by yamagiconf-docgen -type Undocumented. DO NOT EDIT.
+
+package config
+`, string(src))
+}
+
+func TestGenerateErr(t *testing.T) {
+	_, err := generate("testdata/config", "Nope", "config_docs_gen.go")
+	require.ErrorIs(t, err, errTypeNotFound)
+
+	_, err = generate("testdata/nope", "Config", "config_docs_gen.go")
+	require.Error(t, err)
+}
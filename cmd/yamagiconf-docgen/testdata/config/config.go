@@ -0,0 +1,52 @@
+package config
+
+import (
+	"time"
+
+	"github.com/romshark/yamagiconf"
+)
+
+// Config is the configuration of the service.
+type Config struct {
+	// Name is the name of the service.
+	Name string `yaml:"name"`
+
+	// Timeout limits the duration of requests.
+	// Zero means no timeout.
+	Timeout time.Duration `yaml:"timeout"`
+
+	Debug bool `yaml:"debug"` // Debug enables debug logging.
+
+	Servers []*Server `yaml:"servers"`
+
+	Credentials yamagiconf.Secret[Credentials] `yaml:"credentials"`
+
+	Documented string `yaml:"documented" doc:"Documented by the struct tag."`
+
+	Embedded `yaml:",inline"`
+}
+
+type Server struct {
+	// Host is the host name of the server.
+	Host string `yaml:"host"`
+}
+
+type Credentials struct {
+	// User is the name of the user.
+	User string `yaml:"user"`
+}
+
+type Embedded struct {
+	// Region is the deployment region.
+	Region string `yaml:"region"`
+}
+
+// Unreferenced is not referenced by Config.
+type Unreferenced struct {
+	// Field is not included.
+	Field string `yaml:"field"`
+}
+
+type Undocumented struct {
+	Name string `yaml:"name"`
+}
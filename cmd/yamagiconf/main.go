@@ -0,0 +1,214 @@
+// Command yamagiconf provides tooling for YAML configuration files
+// of yamagiconf configuration types.
+//
+// Usage:
+//
+//	yamagiconf fmt -type <package>.<Type> [-options <Func>] [-check] <file>...
+//
+// fmt formats the given files in place using yamagiconf.Format.
+// The package is an import path or a relative directory path
+// (like ./config.Config) of a package of the main module.
+// If -options is set, the files are formatted with the options returned by
+// the exported function Func of the package of the type, which must have
+// the signature `func() []yamagiconf.Option`. Without it, the files are
+// formatted with the default options, so types loaded with options like
+// yamagiconf.WithRules or yamagiconf.WithStrictKeyOrder must provide them
+// through -options for fmt to apply the same rules.
+// If -check is set, fmt reports unformatted files instead of formatting
+// them and exits with code 1 if there are any.
+// Files that fail to load are reported and make fmt exit with code 1.
+//
+// Since Go types can't be resolved at runtime, fmt generates a temporary
+// program in the main module that imports the package, builds and runs it.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+const usage = "usage: yamagiconf fmt -type <package>.<Type> " +
+	"[-options <Func>] [-check] <file>..."
+
+// run runs the command with args and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) < 1 || args[0] != "fmt" {
+		fmt.Fprintln(stderr, usage)
+		return 2
+	}
+	fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fType := fs.String("type", "", "configuration type as <package>.<Type>")
+	fOptions := fs.String("options", "", "exported function of the type's "+
+		"package returning the []yamagiconf.Option to format with")
+	fCheck := fs.Bool("check", false, "list unformatted files and exit with 1 "+
+		"if there are any instead of formatting them")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if *fType == "" || fs.NArg() < 1 {
+		fmt.Fprintln(stderr, usage)
+		return 2
+	}
+	if *fOptions != "" && !token.IsExported(*fOptions) {
+		fmt.Fprintln(stderr, errInvalidOptions)
+		return 2
+	}
+	code, err := runFmt(*fType, *fOptions, *fCheck, fs.Args(), stdout, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "yamagiconf: %v\n", err)
+	}
+	return code
+}
+
+var (
+	errInvalidType    = errors.New("invalid -type, expected <package>.<Type>")
+	errInvalidOptions = errors.New("invalid -options, expected an exported function name")
+)
+
+// runFmt generates, runs and removes the fmt program for type typ
+// formatting with the options returned by function options
+// if it isn't empty.
+func runFmt(
+	typ, options string, check bool, files []string, stdout, stderr io.Writer,
+) (int, error) {
+	i := strings.LastIndexByte(typ, '.')
+	if i < 1 || i == len(typ)-1 || strings.HasSuffix(typ[:i], "/") {
+		return 2, errInvalidType
+	}
+	pkg, typeName := typ[:i], typ[i+1:]
+
+	out, err := exec.Command(
+		"go", "list", "-f", "{{.ImportPath}}\n{{.Module.Dir}}", pkg,
+	).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return 1, fmt.Errorf("resolving package %q: %s",
+				pkg, bytes.TrimSpace(exitErr.Stderr))
+		}
+		return 1, fmt.Errorf("resolving package %q: %w", pkg, err)
+	}
+	importPath, moduleDir, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+
+	src, err := generateProgram(importPath, typeName, options)
+	if err != nil {
+		return 1, err
+	}
+	dir, err := os.MkdirTemp(moduleDir, "yamagiconf-fmt-")
+	if err != nil {
+		return 1, err
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src, 0o644); err != nil {
+		return 1, err
+	}
+
+	bin := filepath.Join(dir, "yamagiconf-fmt")
+	build := exec.Command("go", "build", "-o", bin, "./"+filepath.Base(dir))
+	build.Dir = moduleDir
+	build.Stdout, build.Stderr = stdout, stderr
+	if err := build.Run(); err != nil {
+		return 1, fmt.Errorf("building fmt program: %w", err)
+	}
+
+	var runArgs []string
+	if check {
+		runArgs = append(runArgs, "-check")
+	}
+	runArgs = append(runArgs, "--")
+	runArgs = append(runArgs, files...)
+	cmd := exec.Command(bin, runArgs...)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		return 1, err
+	}
+	return 0, nil
+}
+
+// generateProgram returns the source of the fmt program for type typeName
+// of the package at importPath. If options isn't empty, the program formats
+// with the options returned by the function of the package with that name.
+func generateProgram(importPath, typeName, options string) ([]byte, error) {
+	var b bytes.Buffer
+	err := programTemplate.Execute(&b, struct {
+		ImportPath, TypeName, Options string
+	}{
+		ImportPath: importPath, TypeName: typeName, Options: options,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(b.Bytes())
+}
+
+var programTemplate = template.Must(template.New("program").Parse(`// Code generated by yamagiconf fmt. DO NOT EDIT.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/romshark/yamagiconf"
+
+	config {{printf "%q" .ImportPath}}
+)
+
+func main() {
+	check := flag.Bool("check", false, "")
+	flag.Parse()
+	code := 0
+	for _, path := range flag.Args() {
+		if err := formatFile(path, *check); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+var errUnformatted = fmt.Errorf("not formatted")
+
+func formatFile(path string, check bool) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	formatted, err := yamagiconf.Format[config.{{.TypeName}}](src{{if .Options}}, config.{{.Options}}()...{{end}})
+	if err != nil {
+		return err
+	}
+	switch {
+	case bytes.Equal(src, formatted):
+		return nil
+	case check:
+		return errUnformatted
+	}
+	return os.WriteFile(path, formatted, info.Mode().Perm())
+}
+`))
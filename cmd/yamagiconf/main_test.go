@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateProgram(t *testing.T) {
+	src, err := generateProgram("example.com/svc/config", "Config", "")
+	require.NoError(t, err)
+	require.Contains(t, string(src), `config "example.com/svc/config"`)
+	require.Contains(t, string(src), "yamagiconf.Format[config.Config](src)")
+
+	src, err = generateProgram("example.com/svc/config", "Config", "Options")
+	require.NoError(t, err)
+	require.Contains(t, string(src),
+		"yamagiconf.Format[config.Config](src, config.Options()...)")
+}
+
+func TestRunUsage(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"nope"},
+		{"fmt"},
+		{"fmt", "-type", "./testdata/config.Config"},
+		{"fmt", "-nope"},
+		{"fmt", "-type", "./testdata/config.Config", "-options", "opts", "x.yaml"},
+	} {
+		var stdout, stderr bytes.Buffer
+		require.Equal(t, 2, run(args, &stdout, &stderr), args)
+	}
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 2, run([]string{"fmt", "-type", "Config", "x.yaml"},
+		&stdout, &stderr))
+	require.Contains(t, stderr.String(), errInvalidType.Error())
+}
+
+func TestRunFmt(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program")
+	}
+	dir := t.TempDir()
+	formatted := filepath.Join(dir, "formatted.yaml")
+	unformatted := filepath.Join(dir, "unformatted.yaml")
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(formatted, []byte("name: svc\nport: 80\n"), 0o600))
+	require.NoError(t, os.WriteFile(unformatted, []byte("port: 80\nname: 'svc'\n"), 0o600))
+	require.NoError(t, os.WriteFile(invalid, []byte("name: svc\n"), 0o600))
+
+	typ := "./testdata/config.Config"
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"fmt", "-type", typ, "-check", formatted, unformatted},
+		&stdout, &stderr)
+	require.Equal(t, 1, code, stderr.String())
+	require.Equal(t, unformatted+": not formatted\n", stderr.String())
+
+	stderr.Reset()
+	code = run([]string{"fmt", "-type", typ, formatted, unformatted},
+		&stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	b, err := os.ReadFile(unformatted)
+	require.NoError(t, err)
+	require.Equal(t, "name: svc\nport: 80\n", string(b))
+
+	stderr.Reset()
+	code = run([]string{"fmt", "-type", typ, "-check", formatted, unformatted},
+		&stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+
+	stderr.Reset()
+	code = run([]string{"fmt", "-type", typ, invalid}, &stdout, &stderr)
+	require.Equal(t, 1, code)
+	require.Contains(t, stderr.String(), invalid+": ")
+
+	// Without the options of the type the bool variant is rejected.
+	lax := filepath.Join(dir, "lax.yaml")
+	require.NoError(t, os.WriteFile(lax, []byte("enabled: yes\n"), 0o600))
+	stderr.Reset()
+	code = run([]string{"fmt", "-type", "./testdata/config.LaxConfig", lax},
+		&stdout, &stderr)
+	require.Equal(t, 1, code)
+	require.Contains(t, stderr.String(), lax+": ")
+
+	stderr.Reset()
+	code = run([]string{
+		"fmt", "-type", "./testdata/config.LaxConfig", "-options", "LaxOptions", lax,
+	}, &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	b, err = os.ReadFile(lax)
+	require.NoError(t, err)
+	require.Equal(t, "enabled: true\n", string(b))
+
+	stderr.Reset()
+	code = run([]string{"fmt", "-type", "./testdata/nope.Config", formatted},
+		&stdout, &stderr)
+	require.Equal(t, 1, code)
+	require.Contains(t, stderr.String(), "resolving package")
+}
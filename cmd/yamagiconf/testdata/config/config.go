@@ -0,0 +1,18 @@
+package config
+
+import "github.com/romshark/yamagiconf"
+
+type Config struct {
+	Name string `yaml:"name"`
+	Port uint16 `yaml:"port"`
+}
+
+type LaxConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+func LaxOptions() []yamagiconf.Option {
+	return []yamagiconf.Option{
+		yamagiconf.WithRules(yamagiconf.Rules{AllowBoolVariants: true}),
+	}
+}
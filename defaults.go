@@ -0,0 +1,182 @@
+package yamagiconf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// validateDefaultTag returns an error if the `default` struct tag of f is
+// invalid. A default tag makes a field optional and contains the YAML value
+// of the field in case it's missing in the YAML document. The value is subject
+// to the same rules as any value in the document.
+// Assumes that the type of f has already been validated and
+// that f is neither ignored nor embedded.
+func validateDefaultTag(o *options, path string, f reflect.StructField) error {
+	def, ok := f.Tag.Lookup("default")
+	if yamlTagHasOption(f.Tag, "optional") {
+		// gopkg.in/yaml.v3 fails decoding structs with unknown yaml tag options.
+		return fmt.Errorf("%w: yaml tag option \"optional\" isn't supported, "+
+			"a default struct tag makes the field optional", ErrTypeInvalidDefaultTag)
+	}
+	if !ok {
+		return nil
+	}
+	node, err := parseDefault(def)
+	if err != nil {
+		return fmt.Errorf("%w: %q: %w", ErrTypeInvalidDefaultTag, def, err)
+	}
+	v := &yamlValidation{
+		opts:     o,
+		anchors:  map[string]*anchor{},
+		defaults: map[*yaml.Node]struct{}{},
+	}
+	applyDefaults(f.Type, node, v.defaults)
+	err = validateYAMLValues(v, getYAMLFieldName(f.Tag), path, f.Type, node)
+	if err != nil {
+		return fmt.Errorf("%w: %q: %w", ErrTypeInvalidDefaultTag, def, err)
+	}
+	if len(v.anchors) > 0 {
+		return fmt.Errorf("%w: %q: %w", ErrTypeInvalidDefaultTag, def, errDefaultAnchor)
+	}
+	if err := node.Decode(reflect.New(f.Type).Interface()); err != nil {
+		return fmt.Errorf("%w: %q: %w", ErrTypeInvalidDefaultTag, def, err)
+	}
+	return nil
+}
+
+var (
+	errDefaultEmpty  = errors.New("empty, use '' for an empty string")
+	errDefaultAnchor = errors.New("must not contain anchors and aliases")
+)
+
+// parseDefault parses the value of a `default` struct tag.
+func parseDefault(def string) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(def), &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) < 1 {
+		return nil, errDefaultEmpty
+	}
+	return doc.Content[0], nil
+}
+
+// applyDefaults adds the default values of all fields with a `default`
+// struct tag that are missing in node.
+// The added nodes are located at the mapping node that is missing the field.
+// If added != nil then all added value nodes are added to it.
+// Assumes that tp has already been validated using ValidateType.
+func applyDefaults(tp reflect.Type, node *yaml.Node, added map[*yaml.Node]struct{}) {
+	for tp.Kind() == reflect.Pointer {
+		tp = tp.Elem()
+	}
+	if !typeHasDefaults(tp) {
+		return
+	}
+	node = resolveAlias(node)
+
+	switch tp.Kind() {
+	case reflect.Struct:
+		if node.Kind == yaml.MappingNode {
+			applyDefaultsFields(tp, node, added)
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, n := range node.Content {
+			applyDefaults(tp.Elem(), n, added)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			applyDefaults(tp.Elem(), node.Content[i], added)
+		}
+	}
+}
+
+// applyDefaultsFields applies the defaults of all fields of struct type tp
+// including the fields of embedded inline structs to mapping node.
+func applyDefaultsFields(
+	tp reflect.Type, node *yaml.Node, added map[*yaml.Node]struct{},
+) {
+	for _, f := range getStructInfo(tp).Fields {
+		switch {
+		case f.YAMLTag == "-":
+		case f.Anonymous:
+			// Inline fields share the mapping node with their parent.
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if isPlainStruct(embedded) {
+				applyDefaultsFields(embedded, node, added)
+			}
+		default:
+			n := findContentNodeByTag(node, f.YAMLTag)
+			if n == nil && f.Default != nil {
+				n = copyNodeAt(f.Default, node.Line, node.Column)
+				node.Content = append(node.Content, &yaml.Node{
+					Kind:   yaml.ScalarNode,
+					Tag:    "!!str",
+					Value:  f.YAMLTag,
+					Line:   node.Line,
+					Column: node.Column,
+				}, n)
+				if added != nil {
+					added[n] = struct{}{}
+				}
+			}
+			if n != nil {
+				applyDefaults(f.Type, n, added)
+			}
+		}
+	}
+}
+
+// copyNodeAt returns a deep copy of n with all nodes located at line:column.
+func copyNodeAt(n *yaml.Node, line, column int) *yaml.Node {
+	c := *n
+	c.Line, c.Column = line, column
+	if n.Content != nil {
+		c.Content = make([]*yaml.Node, len(n.Content))
+		for i, n := range n.Content {
+			c.Content[i] = copyNodeAt(n, line, column)
+		}
+	}
+	return &c
+}
+
+var typeHasDefaultsCache sync.Map // reflect.Type -> bool
+
+// typeHasDefaults returns true if tp contains any field
+// with a `default` struct tag.
+// Assumes that tp has already been validated using ValidateType.
+func typeHasDefaults(tp reflect.Type) bool {
+	if v, ok := typeHasDefaultsCache.Load(tp); ok {
+		return v.(bool)
+	}
+	has := false
+	switch tp.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		has = typeHasDefaults(tp.Elem())
+	case reflect.Struct:
+		if !isPlainStruct(tp) {
+			break
+		}
+		for _, f := range getStructInfo(tp).Fields {
+			if f.Default != nil || typeHasDefaults(f.Type) {
+				has = true
+				break
+			}
+		}
+	}
+	typeHasDefaultsCache.Store(tp, has)
+	return has
+}
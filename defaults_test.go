@@ -0,0 +1,233 @@
+package yamagiconf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+type DefaultsConfig struct {
+	Name    string                  `yaml:"name"`
+	Timeout time.Duration           `yaml:"timeout" default:"30s"`
+	Retries int8                    `yaml:"retries" default:"3" validate:"max=5"`
+	Verbose bool                    `yaml:"verbose" default:"false"`
+	Tags    []string                `yaml:"tags" default:"[a, b]"`
+	Proxy   *string                 `yaml:"proxy" default:"null"`
+	Label   string                  `yaml:"label" default:"''" env:"DEFAULTS_TEST_LABEL"`
+	Tuning  DefaultsTuning          `yaml:"tuning" default:"{}"`
+	Pools   map[string]DefaultsPool `yaml:"pools"`
+}
+
+type DefaultsTuning struct {
+	Buffer uint32 `yaml:"buffer" default:"4096"`
+	Level  string `yaml:"level" default:"info"`
+}
+
+type DefaultsPool struct {
+	Size    uint16 `yaml:"size"`
+	MaxIdle uint16 `yaml:"max-idle" default:"2"`
+}
+
+func TestDefaults(t *testing.T) {
+	t.Run("all_defaults", func(t *testing.T) {
+		c, err := LoadSrc[DefaultsConfig](`name: test
+pools:
+  main:
+    size: 10
+`)
+		require.NoError(t, err)
+		require.Equal(t, DefaultsConfig{
+			Name:    "test",
+			Timeout: 30 * time.Second,
+			Retries: 3,
+			Tags:    []string{"a", "b"},
+			Tuning:  DefaultsTuning{Buffer: 4096, Level: "info"},
+			Pools:   map[string]DefaultsPool{"main": {Size: 10, MaxIdle: 2}},
+		}, *c)
+	})
+
+	t.Run("overridden", func(t *testing.T) {
+		c, err := LoadSrc[DefaultsConfig](`name: test
+timeout: 1m
+retries: 1
+verbose: true
+tags: null
+proxy: localhost
+label: foo
+tuning:
+  level: debug
+pools:
+  main:
+    size: 10
+    max-idle: 5
+`)
+		require.NoError(t, err)
+		require.Equal(t, DefaultsConfig{
+			Name:    "test",
+			Timeout: time.Minute,
+			Retries: 1,
+			Verbose: true,
+			Proxy:   PtrTo("localhost"),
+			Label:   "foo",
+			Tuning:  DefaultsTuning{Buffer: 4096, Level: "debug"},
+			Pools:   map[string]DefaultsPool{"main": {Size: 10, MaxIdle: 5}},
+		}, *c)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("DEFAULTS_TEST_LABEL", "from-env")
+		c, err := LoadSrc[DefaultsConfig]("name: test\npools: null\n")
+		require.NoError(t, err)
+		require.Equal(t, "from-env", c.Label)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		c, err := yamagiconf.LoadStream[DefaultsConfig](
+			"name: first\npools: null\n---\nname: second\nretries: 4\npools: null\n",
+		)
+		require.NoError(t, err)
+		require.Len(t, c, 2)
+		require.Equal(t, int8(3), c[0].Retries)
+		require.Equal(t, int8(4), c[1].Retries)
+	})
+
+	t.Run("err_missing_required", func(t *testing.T) {
+		_, err := LoadSrc[DefaultsConfig]("pools: null\n")
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLMissingConfig)
+		require.Equal(t, `at DefaultsConfig.Name (as "name"): `+
+			yamagiconf.ErrYAMLMissingConfig.Error(), err.Error())
+	})
+
+	t.Run("err_missing_in_map_value", func(t *testing.T) {
+		_, err := LoadSrc[DefaultsConfig]("name: test\npools:\n  main:\n    max-idle: 1\n")
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLMissingConfig)
+	})
+}
+
+func TestValidateTypeErrInvalidDefaultTag(t *testing.T) {
+	type Tuning struct {
+		Buffer uint32 `yaml:"buffer"`
+	}
+
+	for _, td := range []struct {
+		name   string
+		check  func() error
+		expect string
+	}{
+		{
+			name: "unparsable_int",
+			check: func() error {
+				type TestConfig struct {
+					Retries int8 `yaml:"retries" default:"three"`
+				}
+				return yamagiconf.ValidateType[TestConfig]()
+			},
+			expect: `at TestConfig.Retries: invalid default struct tag: "three": ` +
+				"yaml: unmarshal errors:\n" +
+				"  line 1: cannot unmarshal !!str `three` into int8",
+		},
+		{
+			name: "overflow",
+			check: func() error {
+				type TestConfig struct {
+					Retries int8 `yaml:"retries" default:"300"`
+				}
+				return yamagiconf.ValidateType[TestConfig]()
+			},
+			expect: `at TestConfig.Retries: invalid default struct tag: "300": ` +
+				"yaml: unmarshal errors:\n" +
+				"  line 1: cannot unmarshal !!int `300` into int8",
+		},
+		{
+			name: "bad_bool_literal",
+			check: func() error {
+				type TestConfig struct {
+					Verbose bool `yaml:"verbose" default:"yes"`
+				}
+				return yamagiconf.ValidateType[TestConfig]()
+			},
+			expect: `at TestConfig.Verbose: invalid default struct tag: "yes": ` +
+				`at 1:1: "verbose" (TestConfig.Verbose): ` +
+				yamagiconf.ErrYAMLBadBoolLiteral.Error(),
+		},
+		{
+			name: "null_on_non_pointer",
+			check: func() error {
+				type TestConfig struct {
+					Name string `yaml:"name" default:"null"`
+				}
+				return yamagiconf.ValidateType[TestConfig]()
+			},
+			expect: `at TestConfig.Name: invalid default struct tag: "null": ` +
+				`at 1:1: "name" (TestConfig.Name): ` +
+				yamagiconf.ErrYAMLNullOnNonPointer.Error(),
+		},
+		{
+			name: "empty",
+			check: func() error {
+				type TestConfig struct {
+					Name string `yaml:"name" default:""`
+				}
+				return yamagiconf.ValidateType[TestConfig]()
+			},
+			expect: `at TestConfig.Name: invalid default struct tag: "": ` +
+				`empty, use '' for an empty string`,
+		},
+		{
+			name: "incomplete_struct",
+			check: func() error {
+				type TestConfig struct {
+					Tuning Tuning `yaml:"tuning" default:"{}"`
+				}
+				return yamagiconf.ValidateType[TestConfig]()
+			},
+			expect: `at TestConfig.Tuning: invalid default struct tag: "{}": ` +
+				`at TestConfig.Tuning.Buffer (as "buffer"): ` +
+				yamagiconf.ErrYAMLMissingConfig.Error(),
+		},
+		{
+			name: "anchor",
+			check: func() error {
+				type TestConfig struct {
+					Tags []string `yaml:"tags" default:"[&a x, *a]"`
+				}
+				return yamagiconf.ValidateType[TestConfig]()
+			},
+			expect: `at TestConfig.Tags: invalid default struct tag: "[&a x, *a]": ` +
+				`must not contain anchors and aliases`,
+		},
+		{
+			name: "ignored_field",
+			check: func() error {
+				type TestConfig struct {
+					Name    string `yaml:"name"`
+					Ignored string `yaml:"-" default:"x"`
+				}
+				return yamagiconf.ValidateType[TestConfig]()
+			},
+			expect: `at TestConfig.Ignored: invalid default struct tag: ` +
+				`on ignored, embedded or unexported field`,
+		},
+		{
+			name: "optional_flag",
+			check: func() error {
+				type TestConfig struct {
+					Timeout time.Duration `yaml:"timeout,optional" default:"30s"`
+				}
+				return yamagiconf.ValidateType[TestConfig]()
+			},
+			expect: `at TestConfig.Timeout: invalid default struct tag: ` +
+				`yaml tag option "optional" isn't supported, ` +
+				`a default struct tag makes the field optional`,
+		},
+	} {
+		t.Run(td.name, func(t *testing.T) {
+			err := td.check()
+			require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidDefaultTag)
+			require.Equal(t, td.expect, err.Error())
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package yamagiconf
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"html/template"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DocsFormat is the output format of Docs.
+type DocsFormat int8
+
+const (
+	_ DocsFormat = iota
+
+	// DocsFormatMarkdown is GitHub flavored Markdown.
+	DocsFormatMarkdown
+
+	// DocsFormatHTML is an HTML fragment.
+	DocsFormatHTML
+)
+
+// docsRestrictions returns the rules that YAML configuration files must follow
+// in addition to the rules of their configuration type.
+func docsRestrictions(r *Rules) []string {
+	var l []string
+	add := func(enforced bool, restriction string) {
+		if enforced {
+			l = append(l, restriction)
+		}
+	}
+	add(!r.AllowBoolVariants, "Booleans must be either `true` or `false`, "+
+		"`yes`, `no`, `on` and `off` are not allowed.")
+	add(!r.AllowNullVariants, "Null values must be written as `null`, "+
+		"`~`, `Null` and other variants are not allowed.")
+	add(!r.AllowNullOnNonPointer, "`null` is only allowed for nullable keys.")
+	add(true, "Keys that aren't part of the configuration are not allowed.")
+	add(!r.AllowYAMLTags, "YAML tags (like `!!str`) are not allowed.")
+	add(r.ForbidAnchors, "Anchors and aliases are not allowed.")
+	add(!r.ForbidAnchors && !r.AllowAnchorRedefinition,
+		"Anchors must not be redeclared.")
+	add(!r.ForbidAnchors && !r.AllowUnusedAnchors,
+		"Anchors must be referenced at least once.")
+	add(!r.ForbidAnchors && !r.AllowAnchorNoValue, "Anchors must have a value.")
+	add(true, "Every key must be present, except for keys with a default value.")
+	add(true, "Values of text types (like `time.Time`) must be strings.")
+	add(!r.AllowEmptyArrayItems, "Arrays must not contain empty items.")
+	add(true, "Files must contain a single document.")
+	add(!r.AllowMergeKeys, "Merge keys (`<<`) are not allowed.")
+	add(r.StrictKeyOrder, "Keys must appear in the order of this table.")
+	add(r.MapKeyConvention != 0, fmt.Sprintf(
+		"Keys of maps with string keys must be %s.", r.MapKeyConvention))
+	add(true, "Env-only secrets must be `null` or empty and set via their env var.")
+	add(true, "Values of keys with an env var are overwritten by the env var "+
+		"if it's set.")
+	return l
+}
+
+// Docs returns the reference documentation of configuration type T
+// in the given format. The documentation consists of a table of all YAML keys
+// in the order of the struct with their Go type, whether they accept `null`,
+// env var, `validate` struct tag, default value and documentation
+// (see RegisterFieldDocs) followed by the restrictions of YAML files.
+// Keys of items are denoted by `[]` for slices and arrays and `<key>` for
+// maps, for example `servers[].host` and `zones.<key>.weight`.
+// The restrictions are those in effect for the loader with opts (see WithRules),
+// which are also used to validate T.
+//
+// Returns ErrDocsFormat if format is not supported.
+func Docs[T any](format DocsFormat, opts ...Option) ([]byte, error) {
+	o := newOptions(opts)
+	if err := validateType[T](o); err != nil {
+		return nil, err
+	}
+	var t T
+	tp := reflect.TypeOf(t)
+	var entries []docsEntry
+	docsFields(tp, "", &entries)
+	d := docsData{
+		Title: tp.Name(), Entries: entries, Restrictions: docsRestrictions(&o.rules),
+	}
+	for _, e := range entries {
+		if e.HasDefault {
+			d.HasDefaults = true
+			break
+		}
+	}
+	switch format {
+	case DocsFormatMarkdown:
+		return d.markdown(), nil
+	case DocsFormatHTML:
+		var b bytes.Buffer
+		if err := docsTemplateHTML.Execute(&b, d); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+	return nil, fmt.Errorf("%w: %d", ErrDocsFormat, format)
+}
+
+type docsData struct {
+	Title        string
+	Entries      []docsEntry
+	HasDefaults  bool
+	Restrictions []string
+}
+
+// docsEntry documents a single YAML key.
+type docsEntry struct {
+	Key        string
+	GoType     string
+	Nullable   bool
+	EnvVar     string
+	Validate   string
+	Default    string
+	HasDefault bool
+	Secret     bool
+	Doc        string
+}
+
+// docsFields adds the entries of the fields of struct type tp including the
+// fields of embedded inline structs to entries.
+// Assumes that tp has already been validated using ValidateType.
+func docsFields(tp reflect.Type, prefix string, entries *[]docsEntry) {
+	for _, f := range getStructInfo(tp).Fields {
+		switch {
+		case f.YAMLTag == "-":
+			continue
+		case f.Anonymous:
+			t := f.Type
+			if t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.Map {
+				*entries = append(*entries, docsEntry{
+					Key:    prefix + "<key>",
+					GoType: t.Elem().String(),
+					Doc:    "Any other key.",
+				})
+				docsType(t.Elem(), prefix+"<key>", entries)
+				continue
+			}
+			docsFields(t, prefix, entries)
+			continue
+		}
+		key := prefix + f.YAMLTag
+		def, hasDefault := f.Tag.Lookup("default")
+		valueType := f.Type
+		if t, ok := secretValueType(valueType); ok {
+			valueType = t
+		}
+		*entries = append(*entries, docsEntry{
+			Key:        key,
+			GoType:     f.Type.String(),
+			Nullable:   isNilableKind(valueType.Kind()),
+			EnvVar:     f.Tag.Get("env"),
+			Validate:   f.Tag.Get("validate"),
+			Default:    def,
+			HasDefault: hasDefault,
+			Secret:     isSecretField(f.StructField),
+			Doc:        fieldDoc(tp, f.StructField),
+		})
+		docsType(f.Type, key, entries)
+	}
+}
+
+// docsType adds the entries of the keys nested in type tp at key to entries.
+func docsType(tp reflect.Type, key string, entries *[]docsEntry) {
+	if t, ok := secretValueType(tp); ok {
+		tp = t
+	}
+	if tp.Kind() == reflect.Pointer {
+		tp = tp.Elem()
+	}
+	if implementsInterface[encoding.TextUnmarshaler](tp) ||
+		implementsInterface[yaml.Unmarshaler](tp) {
+		return
+	}
+	switch tp.Kind() {
+	case reflect.Struct:
+		docsFields(tp, key+".", entries)
+	case reflect.Slice, reflect.Array:
+		docsType(tp.Elem(), key+"[]", entries)
+	case reflect.Map:
+		docsType(tp.Elem(), key+".<key>", entries)
+	}
+}
+
+func isNilableKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+func (d docsData) markdown() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\n", d.Title)
+	b.WriteString("| Key | Type | Nullable | Env | Validate |")
+	if d.HasDefaults {
+		b.WriteString(" Default |")
+	}
+	b.WriteString(" Description |\n|---|---|---|---|---|")
+	if d.HasDefaults {
+		b.WriteString("---|")
+	}
+	b.WriteString("---|\n")
+	for _, e := range d.Entries {
+		nullable := "no"
+		if e.Nullable {
+			nullable = "yes"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |",
+			markdownCode(e.Key), markdownCode(e.GoType), nullable,
+			markdownCode(e.EnvVar), markdownCode(e.Validate))
+		if d.HasDefaults {
+			def := ""
+			if e.HasDefault {
+				def = markdownCode(e.Default)
+			}
+			fmt.Fprintf(&b, " %s |", def)
+		}
+		fmt.Fprintf(&b, " %s |\n", markdownText(e.Description()))
+	}
+	b.WriteString("\n## Restrictions\n\n")
+	for _, r := range d.Restrictions {
+		fmt.Fprintf(&b, "- %s\n", r)
+	}
+	return b.Bytes()
+}
+
+// Description returns the doc text of e including a note for secrets.
+func (e docsEntry) Description() string {
+	if !e.Secret {
+		return e.Doc
+	}
+	if e.Doc == "" {
+		return "Secret."
+	}
+	return e.Doc + " Secret."
+}
+
+// markdownCode returns s as a code span in a Markdown table cell
+// or "" if s is empty.
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(s, "|", `\|`) + "`"
+}
+
+// markdownText returns s escaped for a Markdown table cell.
+func markdownText(s string) string {
+	s = strings.NewReplacer("|", `\|`, "<", "&lt;", ">", "&gt;").Replace(s)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+var docsTemplateHTML = template.Must(template.New("docs").Funcs(template.FuncMap{
+	"restriction": func(s string) template.HTML {
+		// Render `code` spans of the restrictions.
+		parts := strings.Split(template.HTMLEscapeString(s), "`")
+		for i := 1; i < len(parts); i += 2 {
+			parts[i] = "<code>" + parts[i] + "</code>"
+		}
+		return template.HTML(strings.Join(parts, ""))
+	},
+}).Parse(`<h1>{{.Title}}</h1>
+<table>
+<thead>
+<tr><th>Key</th><th>Type</th><th>Nullable</th><th>Env</th><th>Validate</th>
+{{- if .HasDefaults}}<th>Default</th>{{end}}<th>Description</th></tr>
+</thead>
+<tbody>
+{{- range .Entries}}
+<tr><td><code>{{.Key}}</code></td><td><code>{{.GoType}}</code></td>
+{{- if .Nullable}}<td>yes</td>{{else}}<td>no</td>{{end}}
+{{- if .EnvVar}}<td><code>{{.EnvVar}}</code></td>{{else}}<td></td>{{end}}
+{{- if .Validate}}<td><code>{{.Validate}}</code></td>{{else}}<td></td>{{end}}
+{{- if $.HasDefaults}}{{if .HasDefault}}<td><code>{{.Default}}</code></td>
+{{- else}}<td></td>{{end}}{{end}}<td>{{.Description}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+<h2>Restrictions</h2>
+<ul>
+{{- range .Restrictions}}
+<li>{{restriction .}}</li>
+{{- end}}
+</ul>
+`))
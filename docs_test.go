@@ -0,0 +1,116 @@
+package yamagiconf_test
+
+import (
+	"testing"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+type DocsConfig struct {
+	Name         string               `yaml:"name" doc:"Name of the service." validate:"required"`
+	Port         uint16               `yaml:"port" env:"DOCS_TEST_PORT" validate:"min=1|eq=0"`
+	Proxy        *string              `yaml:"proxy" doc:"Proxy <URL>."`
+	Password     string               `yaml:"password" secret:"true"`
+	Servers      []DocsServer         `yaml:"servers"`
+	Zones        map[string]*DocsZone `yaml:"zones"`
+	Ignored      string               `yaml:"-"`
+	DocsEmbedded `yaml:",inline"`
+}
+
+type DocsServer struct {
+	Host string `yaml:"host" default:"localhost"`
+}
+
+type DocsZone struct {
+	Weight uint8 `yaml:"weight"`
+}
+
+type DocsEmbedded struct {
+	Region string `yaml:"region"`
+}
+
+func TestDocsMarkdown(t *testing.T) {
+	d, err := yamagiconf.Docs[DocsConfig](yamagiconf.DocsFormatMarkdown)
+	require.NoError(t, err)
+	require.Equal(t, "# DocsConfig\n\n"+
+		"| Key | Type | Nullable | Env | Validate | Default | Description |\n"+
+		"|---|---|---|---|---|---|---|\n"+
+		"| `name` | `string` | no |  | `required` |  | Name of the service. |\n"+
+		"| `port` | `uint16` | no | `DOCS_TEST_PORT` | `min=1\\|eq=0` |  |  |\n"+
+		"| `proxy` | `*string` | yes |  |  |  | Proxy &lt;URL&gt;. |\n"+
+		"| `password` | `string` | no |  |  |  | Secret. |\n"+
+		"| `servers` | `[]yamagiconf_test.DocsServer` | yes |  |  |  |  |\n"+
+		"| `servers[].host` | `string` | no |  |  | `localhost` |  |\n"+
+		"| `zones` | `map[string]*yamagiconf_test.DocsZone` | yes |  |  |  |  |\n"+
+		"| `zones.<key>.weight` | `uint8` | no |  |  |  |  |\n"+
+		"| `region` | `string` | no |  |  |  |  |\n"+
+		"\n## Restrictions\n\n"+
+		"- Booleans must be either `true` or `false`, "+
+		"`yes`, `no`, `on` and `off` are not allowed.\n"+
+		"- Null values must be written as `null`, `~`, `Null` and other variants "+
+		"are not allowed.\n"+
+		"- `null` is only allowed for nullable keys.\n"+
+		"- Keys that aren't part of the configuration are not allowed.\n"+
+		"- YAML tags (like `!!str`) are not allowed.\n"+
+		"- Anchors must not be redeclared.\n"+
+		"- Anchors must be referenced at least once.\n"+
+		"- Anchors must have a value.\n"+
+		"- Every key must be present, except for keys with a default value.\n"+
+		"- Values of text types (like `time.Time`) must be strings.\n"+
+		"- Arrays must not contain empty items.\n"+
+		"- Files must contain a single document.\n"+
+		"- Merge keys (`<<`) are not allowed.\n"+
+		"- Env-only secrets must be `null` or empty and set via their env var.\n"+
+		"- Values of keys with an env var are overwritten by the env var "+
+		"if it's set.\n", string(d))
+}
+
+func TestDocsMarkdownNoDefaults(t *testing.T) {
+	type TestConfig struct {
+		Name string `yaml:"name"`
+	}
+	d, err := yamagiconf.Docs[TestConfig](yamagiconf.DocsFormatMarkdown)
+	require.NoError(t, err)
+	require.Contains(t, string(d), "| Key | Type | Nullable | Env | Validate | "+
+		"Description |\n|---|---|---|---|---|---|\n"+
+		"| `name` | `string` | no |  |  |  |\n")
+}
+
+func TestDocsMarkdownSecretPointer(t *testing.T) {
+	type TestConfig struct {
+		Token yamagiconf.Secret[*string] `yaml:"token"`
+	}
+	d, err := yamagiconf.Docs[TestConfig](yamagiconf.DocsFormatMarkdown)
+	require.NoError(t, err)
+	require.Contains(t, string(d), "| `token` | "+
+		"`yamagiconf.Secret[*string]` | yes |  |  | Secret. |\n")
+}
+
+func TestDocsHTML(t *testing.T) {
+	d, err := yamagiconf.Docs[DocsConfig](yamagiconf.DocsFormatHTML)
+	require.NoError(t, err)
+	s := string(d)
+	require.Contains(t, s, "<h1>DocsConfig</h1>")
+	require.Contains(t, s, "<tr><td><code>proxy</code></td>"+
+		"<td><code>*string</code></td><td>yes</td><td></td><td></td><td></td>"+
+		"<td>Proxy &lt;URL&gt;.</td></tr>")
+	require.Contains(t, s, "<tr><td><code>servers[].host</code></td>"+
+		"<td><code>string</code></td><td>no</td><td></td><td></td>"+
+		"<td><code>localhost</code></td><td></td></tr>")
+	require.Contains(t, s, "<li>Files must contain a single document.</li>")
+	require.Contains(t, s,
+		"<li>Merge keys (<code>&lt;&lt;</code>) are not allowed.</li>")
+}
+
+func TestDocsErr(t *testing.T) {
+	_, err := yamagiconf.Docs[DocsConfig](yamagiconf.DocsFormat(42))
+	require.ErrorIs(t, err, yamagiconf.ErrDocsFormat)
+
+	type TestConfig struct {
+		Port int `yaml:"port"`
+	}
+	_, err = yamagiconf.Docs[TestConfig](yamagiconf.DocsFormatMarkdown)
+	require.ErrorIs(t, err, yamagiconf.ErrTypeUnsupported)
+}
@@ -0,0 +1,287 @@
+package yamagiconf
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Redacted replaces the values of secret fields in outputs.
+const Redacted = "[REDACTED]"
+
+var errInvalidUTF8 = errors.New("string is not valid UTF-8")
+
+// DumpEffective writes the effective configuration cfg to w as YAML that
+// complies with all rules of LoadFile: fields are written in the order of
+// the struct, every field is written, nil pointers, slices and maps are
+// written as `null` and booleans as either `true` or `false`.
+// If provenance != nil then every value is annotated with a trailing comment
+// stating its source like `# env: DB_PORT`, `# default` or
+// `# config.yaml:12:5`.
+// The values of fields with a `secret` struct tag are replaced by "[REDACTED]",
+// which must be replaced before the output can be loaded again.
+// Type T is validated with opts (see ValidateType), which don't change
+// the output. As with Marshal, map keys aren't converted to
+// Rules.MapKeyConvention.
+func DumpEffective[T any](
+	cfg *T, provenance Provenance, w io.Writer, opts ...Option,
+) error {
+	if cfg == nil {
+		return ErrConfigNil
+	}
+	if err := validateType[T](newOptions(opts)); err != nil {
+		return err
+	}
+	e := nodeEncoder{provenance: provenance, redact: true}
+	node, err := e.encode("", reflect.ValueOf(cfg).Elem())
+	if err != nil {
+		return err
+	}
+	return encodeYAML(w, node)
+}
+
+// encodeYAML writes node to w indented by 2 spaces.
+func encodeYAML(w io.Writer, node *yaml.Node) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// nodeEncoder encodes values into YAML nodes complying with the rules
+// of LoadFile.
+type nodeEncoder struct {
+	// provenance, if not nil, is used to annotate values with their source.
+	provenance Provenance
+
+	// redact enables replacing the values of secret fields by Redacted.
+	redact bool
+}
+
+// encode returns the YAML node of v at yamlPath (see Provenance).
+// Assumes that the type of v has already been validated using ValidateType.
+func (e nodeEncoder) encode(yamlPath string, v reflect.Value) (*yaml.Node, error) {
+	node, err := e.encodeValue(yamlPath, v)
+	if err != nil {
+		return nil, err
+	}
+	if node.Kind == yaml.ScalarNode || node.Style&yaml.FlowStyle != 0 {
+		node.LineComment = e.comment(yamlPath)
+	}
+	return node, nil
+}
+
+func (e nodeEncoder) comment(yamlPath string) string {
+	s, ok := e.provenance[yamlPath]
+	switch {
+	case !ok:
+		return ""
+	case s.EnvVar != "":
+		return "env: " + s.EnvVar
+	}
+	return s.String()
+}
+
+func (e nodeEncoder) encodeValue(yamlPath string, v reflect.Value) (*yaml.Node, error) {
+	tp := v.Type()
+	switch tp.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return newNullNode(), nil
+		}
+	}
+
+	if _, ok := secretValueType(tp); ok {
+		if e.redact {
+			return newStringNode(Redacted), nil
+		}
+		if !v.CanAddr() {
+			c := reflect.New(tp).Elem()
+			c.Set(v)
+			v = c
+		}
+		return e.encodeValue(yamlPath, secretValueOf(v))
+	}
+	if m := asIface[encoding.TextMarshaler](v, false); m != nil {
+		text, err := m.MarshalText()
+		if err != nil {
+			return nil, fmt.Errorf("at %s: %w", yamlPath, err)
+		}
+		return newStringNode(string(text)), nil
+	}
+	if tp == typeTimeDuration {
+		return newStringNode(v.Interface().(fmt.Stringer).String()), nil
+	}
+
+	switch tp.Kind() {
+	case reflect.Pointer:
+		return e.encodeValue(yamlPath, v.Elem())
+	case reflect.Struct:
+		if !isPlainStruct(tp) {
+			break
+		}
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		if err := e.encodeFields(yamlPath, v, node); err != nil {
+			return nil, err
+		}
+		if len(node.Content) < 1 {
+			node.Style = yaml.FlowStyle
+		}
+		return node, nil
+	case reflect.Slice, reflect.Array:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for i := range v.Len() {
+			n, err := e.encode(fmt.Sprintf("%s[%d]", yamlPath, i), v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, n)
+		}
+		if len(node.Content) < 1 {
+			node.Style = yaml.FlowStyle
+		}
+		return node, nil
+	case reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, k := range mapKeysSorted(v) {
+			key, err := e.encodeValue(yamlPath, k)
+			if err != nil {
+				return nil, err
+			}
+			path := fmt.Sprintf("%s[%s]", yamlPath, key.Value)
+			value, err := e.encode(path, v.MapIndex(k))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, key, value)
+		}
+		if len(node.Content) < 1 {
+			node.Style = yaml.FlowStyle
+		}
+		return node, nil
+	}
+
+	if tp.Kind() == reflect.String && asIface[yaml.Marshaler](v, false) == nil {
+		if !utf8.ValidString(v.String()) {
+			// yaml.v3 would encode it as a !!binary tagged value.
+			return nil, fmt.Errorf("at %s: %w", yamlPath, errInvalidUTF8)
+		}
+		// Not using yaml.Node.Encode since it loses strings consisting
+		// of line breaks only.
+		return newStringNode(v.String()), nil
+	}
+
+	// Primitives and implementations of yaml.Marshaler.
+	node := new(yaml.Node)
+	if err := node.Encode(v.Interface()); err != nil {
+		return nil, fmt.Errorf("at %s: %w", yamlPath, err)
+	}
+	return node, nil
+}
+
+// encodeFields adds the fields of struct value v including the fields of
+// embedded inline structs to mapping node.
+func (e nodeEncoder) encodeFields(
+	yamlPath string, v reflect.Value, node *yaml.Node,
+) error {
+	for _, f := range getStructInfo(v.Type()).Fields {
+		fv := v.FieldByIndex(f.Index)
+		switch {
+		case f.YAMLTag == "-":
+			continue
+		case f.Anonymous:
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Map {
+				if err := e.encodeInlineMap(yamlPath, fv, node); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := e.encodeFields(yamlPath, fv, node); err != nil {
+				return err
+			}
+			continue
+		}
+		path := yamlPath + "." + f.YAMLTag
+		if yamlPath == "" {
+			path = f.YAMLTag // Field of the root struct.
+		}
+		var value *yaml.Node
+		switch {
+		case e.redact && isSecretField(f.StructField):
+			value = newStringNode(Redacted)
+			value.LineComment = e.comment(path)
+		case isEnvOnlySecretField(f.StructField):
+			value = newStringNode("")
+			if isNilableKind(f.Type.Kind()) {
+				value = newNullNode()
+			}
+		default:
+			var err error
+			if value, err = e.encode(path, fv); err != nil {
+				return err
+			}
+		}
+		node.Content = append(node.Content, newStringNode(f.YAMLTag), value)
+	}
+	return nil
+}
+
+// encodeInlineMap adds the entries of embedded inline map m to mapping node.
+func (e nodeEncoder) encodeInlineMap(
+	yamlPath string, m reflect.Value, node *yaml.Node,
+) error {
+	for _, k := range mapKeysSorted(m) {
+		key, err := e.encodeValue(yamlPath, k)
+		if err != nil {
+			return err
+		}
+		path := yamlPath + "." + key.Value
+		if yamlPath == "" {
+			path = key.Value
+		}
+		value, err := e.encode(path, m.MapIndex(k))
+		if err != nil {
+			return err
+		}
+		node.Content = append(node.Content, key, value)
+	}
+	return nil
+}
+
+func newStringNode(s string) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+	setStringStyle(n)
+	return n
+}
+
+// setStringStyle makes string scalar node n double quoted if it contains
+// line breaks because yaml.v3 doesn't always preserve them in block scalars,
+// if it would be read as a boolean by YAML 1.1 parsers (like `yes`)
+// and if it looks like a null variant (like `~` or `NULL`) that
+// validateValue would reject unquoted.
+// Other strings are quoted by the encoder if necessary.
+func setStringStyle(n *yaml.Node) {
+	v := n.Value
+	if strings.ContainsAny(v, "\n\r") || v == "~" || strings.EqualFold(v, "null") {
+		n.Style = yaml.DoubleQuotedStyle
+		return
+	}
+	switch strings.ToLower(n.Value) {
+	case "y", "yes", "n", "no", "on", "off":
+		n.Style = yaml.DoubleQuotedStyle
+	}
+}
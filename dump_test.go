@@ -0,0 +1,129 @@
+package yamagiconf_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+type DumpConfig struct {
+	Name         string             `yaml:"name"`
+	Quoted       string             `yaml:"quoted"`
+	Debug        bool               `yaml:"debug"`
+	Timeout      time.Duration      `yaml:"timeout" default:"30s"`
+	Port         uint16             `yaml:"port" env:"DUMP_TEST_PORT"`
+	Ratio        float64            `yaml:"ratio"`
+	Password     string             `yaml:"password" secret:"true"`
+	Proxy        *string            `yaml:"proxy"`
+	Tags         []string           `yaml:"tags"`
+	Empty        []string           `yaml:"empty"`
+	Labels       map[string]string  `yaml:"labels"`
+	Servers      []DumpServer       `yaml:"servers"`
+	Text         ValidatedString    `yaml:"text"`
+	Ignored      string             `yaml:"-"`
+	Limits       map[string]*uint32 `yaml:"limits"`
+	DumpEmbedded `yaml:",inline"`
+}
+
+type DumpEmbedded struct {
+	Region string `yaml:"region"`
+}
+
+type DumpServer struct {
+	Host string `yaml:"host"`
+	TLS  bool   `yaml:"tls"`
+}
+
+const dumpTestSrc = `name: test
+quoted: 'true'
+debug: false
+port: 8080
+ratio: 0.5
+password: hunter2
+proxy: null
+tags:
+  - a
+  - b
+empty: []
+labels:
+  z: last
+  a: first
+servers:
+  - host: a.local
+    tls: true
+text: valid
+limits:
+  b: 2
+  a: null
+region: eu
+`
+
+func TestDumpEffective(t *testing.T) {
+	t.Setenv("DUMP_TEST_PORT", "9090")
+
+	var c DumpConfig
+	p, err := yamagiconf.LoadWithProvenance(dumpTestSrc, &c)
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+	require.NoError(t, yamagiconf.DumpEffective(&c, p, &b))
+	require.Equal(t, `name: test # 1:7
+quoted: "true" # 2:9
+debug: false # 3:8
+timeout: 30s # default
+port: 9090 # env: DUMP_TEST_PORT
+ratio: 0.5 # 5:8
+password: '[REDACTED]' # 6:11
+proxy: null # 7:8
+tags:
+  - a # 9:5
+  - b # 10:5
+empty: [] # 11:8
+labels:
+  a: first # 14:6
+  z: last # 13:6
+servers:
+  - host: a.local # 16:11
+    tls: true # 17:10
+text: valid # 18:7
+limits:
+  a: null # 21:6
+  b: 2 # 20:6
+region: eu # 22:9
+`, b.String())
+}
+
+func TestDumpEffectiveRoundTrip(t *testing.T) {
+	var c DumpConfig
+	require.NoError(t, yamagiconf.Load(dumpTestSrc, &c))
+	c.Password = "" // Avoid redaction.
+
+	var b bytes.Buffer
+	require.NoError(t, yamagiconf.DumpEffective(&c, nil, &b))
+	require.False(t, strings.Contains(b.String(), "#"))
+
+	// Secrets are redacted and can't be loaded back as is.
+	src := strings.ReplaceAll(b.String(), "'[REDACTED]'", "''")
+	var loaded DumpConfig
+	require.NoError(t, yamagiconf.Load(src, &loaded))
+	require.Equal(t, c, loaded)
+}
+
+func TestDumpEffectiveErr(t *testing.T) {
+	var b bytes.Buffer
+	err := yamagiconf.DumpEffective[DumpConfig](nil, nil, &b)
+	require.ErrorIs(t, err, yamagiconf.ErrConfigNil)
+
+	type TestConfig struct {
+		Password string `yaml:"password" secret:"yes"`
+	}
+	err = yamagiconf.DumpEffective(&TestConfig{}, nil, &b)
+	require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidSecretTag)
+	require.Equal(t, `at TestConfig.Password: invalid secret struct tag: `+
+		`"yes", expected "true" or "env-only"`, err.Error())
+}
@@ -0,0 +1,37 @@
+package yamagiconf
+
+import (
+	"reflect"
+	"sync"
+)
+
+var fieldDocs sync.Map // reflect.Type -> map[string]string
+
+// RegisterFieldDocs registers the documentation of the fields of struct
+// type T by Go field name for JSONSchema, Template and Docs.
+// A `doc` struct tag takes precedence over registered documentation.
+// RegisterFieldDocs is usually called by code generated with
+// cmd/yamagiconf-docgen from the doc comments of the fields:
+//
+//	//go:generate go run github.com/romshark/yamagiconf/cmd/yamagiconf-docgen -type Config
+//
+// Registering the documentation of T again replaces it.
+func RegisterFieldDocs[T any](docs map[string]string) {
+	var t T
+	c := make(map[string]string, len(docs))
+	for k, v := range docs {
+		c[k] = v
+	}
+	fieldDocs.Store(reflect.TypeOf(t), c)
+}
+
+// fieldDoc returns the documentation of field f of struct type tp.
+func fieldDoc(tp reflect.Type, f reflect.StructField) string {
+	if doc, ok := f.Tag.Lookup("doc"); ok {
+		return doc
+	}
+	if docs, ok := fieldDocs.Load(tp); ok {
+		return docs.(map[string]string)[f.Name]
+	}
+	return ""
+}
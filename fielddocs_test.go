@@ -0,0 +1,47 @@
+package yamagiconf_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+type FieldDocsConfig struct {
+	Name   string `yaml:"name"`
+	Port   uint16 `yaml:"port" doc:"Port from the struct tag."`
+	Region string `yaml:"region"`
+}
+
+func TestRegisterFieldDocs(t *testing.T) {
+	yamagiconf.RegisterFieldDocs[FieldDocsConfig](map[string]string{
+		"Name": "Name of the service.",
+		"Port": "Ignored in favor of the struct tag.",
+	})
+
+	require.Equal(t, `# Name of the service.
+name: ""
+# Port from the struct tag.
+port: 0
+region: ""
+`, string(yamagiconf.Template[FieldDocsConfig]()))
+
+	s, err := yamagiconf.JSONSchema[FieldDocsConfig]()
+	require.NoError(t, err)
+	var schema struct {
+		Properties map[string]struct {
+			Description string `json:"description"`
+		} `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(s, &schema))
+	require.Equal(t, "Name of the service.", schema.Properties["name"].Description)
+	require.Equal(t, "Port from the struct tag.", schema.Properties["port"].Description)
+	require.Equal(t, "", schema.Properties["region"].Description)
+
+	d, err := yamagiconf.Docs[FieldDocsConfig](yamagiconf.DocsFormatMarkdown)
+	require.NoError(t, err)
+	require.Contains(t, string(d), "| `name` | `string` | no |  |  | "+
+		"Name of the service. |\n")
+}
@@ -0,0 +1,235 @@
+package yamagiconf
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format returns YAML document src in canonical form:
+//   - keys of structs are sorted in the order of the struct fields, keys of
+//     maps keep their order.
+//   - collections are indented by 2 spaces, empty collections are written as
+//     `[]` and `{}` and all other collections in block style.
+//   - strings are only quoted if necessary using double quotes, literal and
+//     folded block strings are preserved.
+//   - booleans are written as `true` and `false` and nulls as `null`
+//     (see Rules.AllowBoolVariants and Rules.AllowNullVariants).
+//
+// Comments, anchors and aliases are preserved. The comment preceding the first
+// key of the file is kept at the top of the file as a header unless the file
+// has a header separated from it by an empty line, in which case it stays with
+// its key.
+// Returns the error of Load if src doesn't pass Load for T
+// and ErrFormatAliasOrder if reordering keys would move an alias before
+// its anchor. Merge keys (see Rules.AllowMergeKeys) keep their position.
+func Format[T any](src []byte, opts ...Option) ([]byte, error) {
+	o := newOptions(opts)
+	if err := validateType[T](o); err != nil {
+		return nil, err
+	}
+	var original T
+	if err := load(context.Background(), o, src, &original, "", nil); err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := newDecoderYAML(src).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrYAMLMalformed, err)
+	}
+	root := doc.Content[0]
+	var first *yaml.Node
+	if root.Kind == yaml.MappingNode && len(root.Content) > 0 {
+		first = root.Content[0]
+	}
+	formatNode(reflect.TypeOf(original), root)
+	if first != nil && root.Content[0] != first &&
+		first.HeadComment != "" && doc.HeadComment == "" {
+		// Without a header separated by an empty line the comment preceding
+		// the first key is the header of the file and must not move along
+		// with the key.
+		doc.HeadComment, first.HeadComment = first.HeadComment, ""
+	}
+
+	if err := checkAliasOrder(&doc); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	if err := encodeYAML(&b, &doc); err != nil {
+		return nil, err
+	}
+	var formatted T
+	if err := load(context.Background(), o, b.Bytes(), &formatted, "", nil); err != nil {
+		return nil, fmt.Errorf("formatted document fails to load: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// checkAliasOrder returns ErrFormatAliasOrder if any alias in document
+// precedes its anchor.
+func checkAliasOrder(document *yaml.Node) error {
+	defined := map[*yaml.Node]struct{}{}
+	var check func(n *yaml.Node) error
+	check = func(n *yaml.Node) error {
+		if n.Kind == yaml.AliasNode {
+			if _, ok := defined[n.Alias]; !ok {
+				return fmt.Errorf("%w: alias %q of anchor at %d:%d",
+					ErrFormatAliasOrder, n.Value, n.Alias.Line, n.Alias.Column)
+			}
+			return nil
+		}
+		if n.Anchor != "" {
+			defined[n] = struct{}{}
+		}
+		for _, c := range n.Content {
+			if err := check(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return check(document)
+}
+
+// formatNode formats node of type tp in place.
+// Assumes that tp has already been validated using ValidateType
+// and node passed validateYAMLValues.
+func formatNode(tp reflect.Type, node *yaml.Node) {
+	if t, ok := secretValueType(tp); ok {
+		tp = t
+	}
+	if tp.Kind() == reflect.Pointer {
+		tp = tp.Elem()
+	}
+	switch node.Kind {
+	case yaml.AliasNode:
+		return
+	case yaml.ScalarNode:
+		if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+			return
+		}
+		formatScalarValue(tp, node)
+		// Let the encoder decide whether quotes are necessary.
+		node.Style = 0
+		if node.Tag == "!!str" {
+			setStringStyle(node)
+		}
+		return
+	}
+	if len(node.Content) > 0 {
+		node.Style &^= yaml.FlowStyle
+	}
+	if implementsInterface[encoding.TextUnmarshaler](tp) ||
+		implementsInterface[yaml.Unmarshaler](tp) {
+		return
+	}
+	switch tp.Kind() {
+	case reflect.Struct:
+		formatFields(tp, node)
+	case reflect.Slice, reflect.Array:
+		for _, n := range node.Content {
+			formatNode(tp.Elem(), n)
+		}
+	case reflect.Map:
+		for i := 0; i < len(node.Content); i += 2 {
+			formatNode(tp.Key(), node.Content[i])
+			formatNode(tp.Elem(), node.Content[i+1])
+		}
+	}
+}
+
+// formatScalarValue rewrites the null literal variants like `~` to `null`
+// and the boolean literal variants like `yes` (see Rules.AllowBoolVariants)
+// of values of type tp to `true` and `false`.
+func formatScalarValue(tp reflect.Type, node *yaml.Node) {
+	if node.Tag == "!!null" {
+		if node.Value != "" {
+			node.Value, node.Tag = "null", ""
+		}
+		return
+	}
+	if tp.Kind() != reflect.Bool ||
+		implementsInterface[encoding.TextUnmarshaler](tp) ||
+		implementsInterface[yaml.Unmarshaler](tp) {
+		return
+	}
+	var b bool
+	if err := node.Decode(&b); err == nil {
+		node.Value, node.Tag = strconv.FormatBool(b), "!!bool"
+	}
+}
+
+// formatFields sorts the key-value pairs of mapping node of struct type tp
+// in the order of the struct fields and formats the values.
+// Keys of embedded inline maps follow in their original order.
+// Merge keys keep their original position.
+func formatFields(tp reflect.Type, node *yaml.Node) {
+	pairs := make(map[string][2]*yaml.Node, len(node.Content)/2)
+	var merges []int // Indexes of the merge keys.
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Tag == "!!merge" {
+			merges = append(merges, i)
+			// Let the encoder write `<<` instead of `!!merge <<`.
+			node.Content[i].Tag = ""
+			if v := node.Content[i+1]; v.Kind == yaml.MappingNode {
+				formatNode(tp, v)
+			}
+			continue
+		}
+		pairs[node.Content[i].Value] = [2]*yaml.Node{
+			node.Content[i], node.Content[i+1],
+		}
+	}
+	sorted := make([]*yaml.Node, 0, len(node.Content))
+	var inlineMap reflect.Type
+	var add func(tp reflect.Type)
+	add = func(tp reflect.Type) {
+		for _, f := range getStructInfo(tp).Fields {
+			switch {
+			case f.YAMLTag == "-":
+				continue
+			case f.Anonymous:
+				t := f.Type
+				if t.Kind() == reflect.Pointer {
+					t = t.Elem()
+				}
+				if t.Kind() == reflect.Map {
+					inlineMap = t
+					continue
+				}
+				add(t)
+				continue
+			}
+			p, ok := pairs[f.YAMLTag]
+			if !ok {
+				continue // Field with a default value.
+			}
+			delete(pairs, f.YAMLTag)
+			formatNode(f.Type, p[1])
+			sorted = append(sorted, p[0], p[1])
+		}
+	}
+	add(tp)
+	if inlineMap != nil {
+		for i := 0; i < len(node.Content); i += 2 {
+			_, ok := pairs[node.Content[i].Value]
+			if ok && !slices.Contains(merges, i) {
+				formatNode(inlineMap.Elem(), node.Content[i+1])
+				sorted = append(sorted, node.Content[i], node.Content[i+1])
+			}
+		}
+	}
+	for _, i := range merges {
+		sorted = append(sorted[:i], append(
+			[]*yaml.Node{node.Content[i], node.Content[i+1]}, sorted[i:]...,
+		)...)
+	}
+	node.Content = sorted
+}
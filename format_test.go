@@ -0,0 +1,286 @@
+package yamagiconf_test
+
+import (
+	"testing"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+type FormatConfig struct {
+	Name           string            `yaml:"name"`
+	Debug          bool              `yaml:"debug"`
+	Proxy          *string           `yaml:"proxy"`
+	Tags           []string          `yaml:"tags"`
+	Labels         map[string]string `yaml:"labels"`
+	Servers        []FormatServer    `yaml:"servers"`
+	Text           string            `yaml:"text"`
+	FormatEmbedded `yaml:",inline"`
+}
+
+type FormatServer struct {
+	Host string `yaml:"host"`
+	Port uint16 `yaml:"port"`
+}
+
+type FormatEmbedded struct {
+	Region string `yaml:"region"`
+}
+
+func TestFormat(t *testing.T) {
+	src := `# Service configuration.
+region: 'eu'
+servers:
+        - port: 80 # HTTP
+          host: "a.local"
+        - {port: 81, host: 'b.local'}
+labels: {z: 'last', a: "first"}
+tags: ['a', "null", yes]
+# Debug mode.
+debug: false
+proxy: null
+text: |
+    multi
+    line
+name: "svc"
+`
+	b, err := yamagiconf.Format[FormatConfig]([]byte(src))
+	require.NoError(t, err)
+	require.Equal(t, `# Service configuration.
+
+name: svc
+# Debug mode.
+debug: false
+proxy: null
+tags:
+  - a
+  - "null"
+  - "yes"
+labels:
+  z: last
+  a: first
+servers:
+  - host: a.local
+    port: 80 # HTTP
+  - host: b.local
+    port: 81
+text: |
+  multi
+  line
+region: eu
+`, string(b))
+
+	// Formatting is idempotent.
+	again, err := yamagiconf.Format[FormatConfig](b)
+	require.NoError(t, err)
+	require.Equal(t, string(b), string(again))
+
+	var expect, actual FormatConfig
+	require.NoError(t, yamagiconf.Load(src, &expect))
+	require.NoError(t, yamagiconf.Load(b, &actual))
+	require.Equal(t, expect, actual)
+}
+
+func TestFormatHeaderComment(t *testing.T) {
+	type Config struct {
+		A string `yaml:"a"`
+		B string `yaml:"b"`
+	}
+
+	t.Run("header", func(t *testing.T) {
+		b, err := yamagiconf.Format[Config]([]byte(`# Header.
+b: two # B
+# Comment of a.
+a: one
+`))
+		require.NoError(t, err)
+		require.Equal(t, `# Header.
+
+# Comment of a.
+a: one
+b: two # B
+`, string(b))
+
+		again, err := yamagiconf.Format[Config](b)
+		require.NoError(t, err)
+		require.Equal(t, string(b), string(again))
+	})
+
+	t.Run("header_and_key_comment", func(t *testing.T) {
+		// The comment of the first key stays with the key
+		// if the file has a header separated by an empty line.
+		b, err := yamagiconf.Format[Config]([]byte(`# License header.
+
+# About b.
+b: two # B
+# Comment of a.
+a: one
+`))
+		require.NoError(t, err)
+		require.Equal(t, `# License header.
+
+# Comment of a.
+a: one
+# About b.
+b: two # B
+`, string(b))
+
+		again, err := yamagiconf.Format[Config](b)
+		require.NoError(t, err)
+		require.Equal(t, string(b), string(again))
+	})
+
+	t.Run("not_moved", func(t *testing.T) {
+		// The comment stays with the first key if it isn't moved.
+		b, err := yamagiconf.Format[Config]([]byte("# Top comment.\na: one\nb: two\n"))
+		require.NoError(t, err)
+		require.Equal(t, "# Top comment.\na: one\nb: two\n", string(b))
+	})
+}
+
+func TestFormatMergeKeys(t *testing.T) {
+	type Point struct {
+		X int32 `yaml:"x"`
+		Y int32 `yaml:"y"`
+	}
+	type Config struct {
+		B Point `yaml:"b"`
+		A Point `yaml:"a"`
+		C Point `yaml:"c"`
+	}
+	opt := yamagiconf.WithRules(yamagiconf.Rules{AllowMergeKeys: true})
+	b, err := yamagiconf.Format[Config]([]byte(`b: &b {y: 2, x: 1}
+a: {<<: *b}
+c:
+  y: 3
+  <<: [*b]
+`), opt)
+	require.NoError(t, err)
+	require.Equal(t, `b: &b
+  x: 1
+  y: 2
+a:
+  <<: *b
+c:
+  y: 3
+  <<: [*b]
+`, string(b))
+
+	again, err := yamagiconf.Format[Config](b, opt)
+	require.NoError(t, err)
+	require.Equal(t, string(b), string(again))
+}
+
+func TestFormatLiteralVariants(t *testing.T) {
+	type Config struct {
+		A     bool              `yaml:"a"`
+		B     bool              `yaml:"b"`
+		C     *bool             `yaml:"c"`
+		S     string            `yaml:"s"`
+		P     *string           `yaml:"p"`
+		Q     *string           `yaml:"q"`
+		Flags map[string]bool   `yaml:"flags"`
+		Names map[string]*int32 `yaml:"names"`
+	}
+	opt := yamagiconf.WithRules(yamagiconf.Rules{
+		AllowBoolVariants: true,
+		AllowNullVariants: true,
+	})
+	b, err := yamagiconf.Format[Config]([]byte(`a: yes
+b: Off
+c: ~
+s: "~"
+p: Null
+q: NULL
+flags: {u: "on", v: N, w: TRUE}
+names: {u: ~, v: 1}
+`), opt)
+	require.NoError(t, err)
+	require.Equal(t, `a: true
+b: false
+c: null
+s: "~"
+p: null
+q: null
+flags:
+  u: true
+  v: false
+  w: true
+names:
+  u: null
+  v: 1
+`, string(b))
+
+	// The output complies with the default rules.
+	again, err := yamagiconf.Format[Config](b)
+	require.NoError(t, err)
+	require.Equal(t, string(b), string(again))
+}
+
+func TestFormatQuotedLookAlikes(t *testing.T) {
+	type Config struct {
+		Tilde string            `yaml:"tilde"`
+		Null  string            `yaml:"null"`
+		Upper string            `yaml:"upper"`
+		Mixed *string           `yaml:"mixed"`
+		Yes   string            `yaml:"yes"`
+		On    string            `yaml:"on"`
+		Names []string          `yaml:"names"`
+		Map   map[string]string `yaml:"map"`
+	}
+	b, err := yamagiconf.Format[Config]([]byte(`tilde: '~'
+null: 'null'
+upper: "NULL"
+mixed: "nUll"
+yes: 'yes'
+on: "On"
+names: ['Null', "n", 'true']
+map: {k: 'NuLL'}
+`))
+	require.NoError(t, err)
+	require.Equal(t, `tilde: "~"
+null: "null"
+upper: "NULL"
+mixed: "nUll"
+yes: "yes"
+on: "On"
+names:
+  - "Null"
+  - "n"
+  - "true"
+map:
+  k: "NuLL"
+`, string(b))
+
+	again, err := yamagiconf.Format[Config](b)
+	require.NoError(t, err)
+	require.Equal(t, string(b), string(again))
+}
+
+func TestFormatErr(t *testing.T) {
+	_, err := yamagiconf.Format[FormatConfig]([]byte("name: svc\n"))
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLMissingConfig)
+
+	_, err = yamagiconf.Format[FormatConfig]([]byte(`name: &n svc
+debug: yes
+proxy: null
+tags: []
+labels: {}
+servers: []
+text: *n
+region: eu
+`))
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLBadBoolLiteral)
+
+	_, err = yamagiconf.Format[FormatConfig]([]byte(`region: &r eu
+name: *r
+debug: false
+proxy: null
+tags: []
+labels: {}
+servers: []
+text: ""
+`))
+	require.ErrorIs(t, err, yamagiconf.ErrFormatAliasOrder)
+}
@@ -0,0 +1,500 @@
+package yamagiconf
+
+import (
+	"encoding"
+	"encoding/json"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JSONSchemaDialect is the URI of the JSON Schema dialect used by JSONSchema.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns a JSON Schema (draft 2020-12) describing the YAML files
+// accepted by LoadFile for T to provide completion and validation in editors
+// (for example using yaml-language-server).
+//
+//   - The documentation of a field (see RegisterFieldDocs) is the description
+//     of its property.
+//   - Structs are objects with `additionalProperties: false` that require every
+//     field except fields with a `default` struct tag.
+//     Fields of embedded inline structs are properties of the parent object.
+//   - Only pointers, slices and maps accept `null`.
+//   - Integers are limited to the range of their Go type.
+//   - time.Duration is a string with `format: duration`.
+//   - Types implementing encoding.TextUnmarshaler are strings and types
+//     implementing yaml.Unmarshaler accept any value.
+//   - The common `validate` struct tag rules min, max, gt, gte, lt, lte, len,
+//     eq, oneof, required, email, url, uri, hostname, ipv4, ipv6, uuid,
+//     alpha, alphanum, numeric, hexadecimal, contains, startswith and endswith
+//     are translated into the corresponding keywords (pattern, minimum,
+//     maximum, enum, etc.). Other rules and rules after "dive" are ignored.
+//     Rules after "omitempty" accept the empty value as an alternative
+//     (`anyOf`) unless it's null.
+//
+// The schema can't express all rules of LoadFile, the loader remains
+// the source of truth. opts are only used to validate T (for example to allow
+// int with Rules.AllowIntUint), the schema always describes the default
+// rules and doesn't express Rules.StrictKeyOrder and Rules.MapKeyConvention.
+func JSONSchema[T any](opts ...Option) ([]byte, error) {
+	if err := validateType[T](newOptions(opts)); err != nil {
+		return nil, err
+	}
+	var t T
+	tp := reflect.TypeOf(t)
+	s := jsonSchemaOf(tp)
+	s["$schema"] = JSONSchemaDialect
+	s["title"] = tp.Name()
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// jsonSchema is a JSON Schema object.
+type jsonSchema = map[string]any
+
+// jsonSchemaOf returns the schema of tp.
+// Assumes that tp has already been validated using ValidateType.
+func jsonSchemaOf(tp reflect.Type) jsonSchema {
+	if t, ok := secretValueType(tp); ok {
+		tp = t
+	}
+	if tp.Kind() == reflect.Pointer {
+		return jsonSchemaNullable(jsonSchemaOf(tp.Elem()))
+	}
+	if tp == typeTimeDuration {
+		return jsonSchema{"type": "string", "format": "duration"}
+	}
+	if implementsInterface[encoding.TextUnmarshaler](tp) {
+		return jsonSchema{"type": "string"}
+	}
+	if implementsInterface[yaml.Unmarshaler](tp) {
+		return jsonSchema{}
+	}
+
+	switch tp.Kind() {
+	case reflect.Bool:
+		return jsonSchema{"type": "boolean"}
+	case reflect.String:
+		return jsonSchema{"type": "string"}
+	case reflect.Float32, reflect.Float64:
+		return jsonSchema{"type": "number"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := tp.Bits()
+		return jsonSchema{
+			"type":    "integer",
+			"minimum": int64(-1) << (bits - 1),
+			"maximum": int64(1)<<(bits-1) - 1,
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		return jsonSchema{
+			"type":    "integer",
+			"minimum": 0,
+			"maximum": uint64(math.MaxUint64) >> (64 - tp.Bits()),
+		}
+	case reflect.Array:
+		return jsonSchema{
+			"type":     "array",
+			"items":    jsonSchemaOf(tp.Elem()),
+			"minItems": tp.Len(),
+			"maxItems": tp.Len(),
+		}
+	case reflect.Slice:
+		return jsonSchemaNullable(jsonSchema{
+			"type": "array", "items": jsonSchemaOf(tp.Elem()),
+		})
+	case reflect.Map:
+		s := jsonSchema{
+			"type":                 "object",
+			"additionalProperties": jsonSchemaOf(tp.Elem()),
+		}
+		if k := jsonSchemaMapKey(tp.Key()); k != nil {
+			s["propertyNames"] = k
+		}
+		return jsonSchemaNullable(s)
+	case reflect.Struct:
+		s := jsonSchema{"type": "object"}
+		properties := jsonSchema{}
+		required := []string{}
+		var additional any = false
+		jsonSchemaFields(tp, properties, &required, &additional)
+		s["properties"] = properties
+		s["required"] = required
+		s["additionalProperties"] = additional
+		return s
+	}
+	return jsonSchema{}
+}
+
+// jsonSchemaFields adds the properties of the fields of struct type tp
+// including the fields of embedded inline structs to properties.
+// The schema of embedded inline map values is written to additional.
+func jsonSchemaFields(
+	tp reflect.Type, properties jsonSchema, required *[]string, additional *any,
+) {
+	for _, f := range getStructInfo(tp).Fields {
+		switch {
+		case f.YAMLTag == "-":
+			continue
+		case f.Anonymous:
+			t := f.Type
+			if t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.Map {
+				*additional = jsonSchemaOf(t.Elem())
+				continue
+			}
+			jsonSchemaFields(t, properties, required, additional)
+			continue
+		}
+		var s jsonSchema
+		if isEnvOnlySecretField(f.StructField) {
+			s = jsonSchemaEnvOnlySecret(f.Type)
+		} else {
+			s = jsonSchemaOf(f.Type)
+			jsonSchemaApplyValidateTag(s, f.Type, f.Tag.Get("validate"))
+		}
+		if doc := fieldDoc(tp, f.StructField); doc != "" {
+			s["description"] = doc
+		}
+		if f.Default != nil {
+			var def any
+			if err := f.Default.Decode(&def); err == nil {
+				s["default"] = def
+			}
+		} else {
+			*required = append(*required, f.YAMLTag)
+		}
+		properties[f.YAMLTag] = s
+	}
+}
+
+// jsonSchemaNullable makes s additionally accept null.
+func jsonSchemaNullable(s jsonSchema) jsonSchema {
+	t, ok := s["type"].(string)
+	if !ok {
+		return jsonSchema{"anyOf": []any{s, jsonSchema{"type": "null"}}}
+	}
+	s["type"] = []string{t, "null"}
+	if e, ok := s["enum"].([]any); ok {
+		s["enum"] = append(e, nil)
+	}
+	return s
+}
+
+// jsonSchemaNonNullable makes s, made nullable by jsonSchemaNullable,
+// reject null.
+func jsonSchemaNonNullable(s jsonSchema) {
+	if a, ok := s["anyOf"].([]any); ok && len(a) == 2 {
+		if n, ok := a[1].(jsonSchema); ok && n["type"] == "null" {
+			delete(s, "anyOf")
+			for k, v := range a[0].(jsonSchema) {
+				s[k] = v
+			}
+			return
+		}
+	}
+	if t, ok := s["type"].([]string); ok {
+		if t = slices.DeleteFunc(slices.Clone(t), func(t string) bool {
+			return t == "null"
+		}); len(t) == 1 {
+			s["type"] = t[0]
+		} else {
+			s["type"] = t
+		}
+	}
+	if e, ok := s["enum"].([]any); ok {
+		s["enum"] = slices.DeleteFunc(slices.Clone(e), func(v any) bool {
+			return v == nil
+		})
+	}
+}
+
+// jsonSchemaEnvOnlySecret returns the schema of a field of type tp tagged
+// `secret:"env-only"`, which only accepts null for nilable types
+// and the empty string otherwise.
+func jsonSchemaEnvOnlySecret(tp reflect.Type) jsonSchema {
+	if t, ok := secretValueType(tp); ok {
+		tp = t
+	}
+	if isNilableKind(tp.Kind()) {
+		return jsonSchema{"type": "null"}
+	}
+	return jsonSchema{"type": "string", "maxLength": 0}
+}
+
+// jsonSchemaMapKey returns the schema of the property names of maps with
+// keys of type tp or nil if any property name is accepted.
+func jsonSchemaMapKey(tp reflect.Type) jsonSchema {
+	if implementsInterface[encoding.TextUnmarshaler](tp) {
+		return nil
+	}
+	switch tp.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return jsonSchema{"pattern": `^-?[0-9]+$`}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		return jsonSchema{"pattern": `^[0-9]+$`}
+	case reflect.Bool:
+		return jsonSchema{"enum": []any{"true", "false"}}
+	}
+	return nil
+}
+
+// jsonSchemaValidateFormats maps validate rules to JSON Schema formats.
+var jsonSchemaValidateFormats = map[string]string{
+	"email":    "email",
+	"url":      "uri",
+	"uri":      "uri",
+	"hostname": "hostname",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"uuid":     "uuid",
+}
+
+// jsonSchemaValidatePatterns maps validate rules to regular expressions.
+var jsonSchemaValidatePatterns = map[string]string{
+	"alpha":       `^[a-zA-Z]+$`,
+	"alphanum":    `^[a-zA-Z0-9]+$`,
+	"numeric":     `^[-+]?[0-9]+(?:\.[0-9]+)?$`,
+	"hexadecimal": `^(?:0[xX])?[0-9a-fA-F]+$`,
+}
+
+// jsonSchemaApplyValidateTag adds the keywords corresponding to the rules
+// of validate struct tag tag of a field of type tp to s.
+// Rules following `omitempty` on types other than pointers, slices and maps
+// are added as an alternative to the empty value or omitted if the empty value
+// can't be described. `required` makes pointers, slices and maps reject null.
+func jsonSchemaApplyValidateTag(s jsonSchema, tp reflect.Type, tag string) {
+	if t, ok := secretValueType(tp); ok {
+		tp = t
+	}
+	rules := strings.Split(tag, ",")
+	if i := slices.Index(rules, "dive"); i != -1 {
+		rules = rules[:i] // Following rules apply to items.
+	}
+	if isNilableKind(tp.Kind()) && slices.Contains(rules, "required") {
+		jsonSchemaNonNullable(s)
+	}
+	isPtr := tp.Kind() == reflect.Pointer
+	if isPtr {
+		tp = tp.Elem()
+		// `required` only requires pointers to be non-nil.
+		rules = slices.DeleteFunc(rules, func(r string) bool {
+			return r == "required"
+		})
+	}
+	if tp == typeTimeDuration ||
+		implementsInterface[encoding.TextUnmarshaler](tp) ||
+		implementsInterface[yaml.Unmarshaler](tp) {
+		return // Rules apply to the Go value, not the YAML text.
+	}
+	i := slices.Index(rules, "omitempty")
+	if i == -1 || isPtr || isNilableKind(tp.Kind()) {
+		// Null is the empty value of pointers, slices and maps
+		// and isn't constrained by the keywords of the other types.
+		jsonSchemaApplyRules(s, tp, rules)
+		return
+	}
+	jsonSchemaApplyRules(s, tp, rules[:i])
+	empty := jsonSchemaEmpty(tp)
+	if empty == nil {
+		return
+	}
+	nonEmpty := jsonSchema{}
+	jsonSchemaApplyRules(nonEmpty, tp, rules[i+1:])
+	if len(nonEmpty) > 0 {
+		s["anyOf"] = []any{empty, nonEmpty}
+	}
+}
+
+// jsonSchemaEmpty returns the schema accepting only the values of type tp
+// that are empty by the definition of the validate rule `omitempty`
+// or nil if there is none.
+func jsonSchemaEmpty(tp reflect.Type) jsonSchema {
+	switch {
+	case tp.Kind() == reflect.String:
+		return jsonSchema{"const": ""}
+	case tp.Kind() == reflect.Bool:
+		return jsonSchema{"const": false}
+	case isNumberKind(tp.Kind()):
+		return jsonSchema{"const": 0}
+	}
+	return nil
+}
+
+// jsonSchemaApplyRules adds the keywords corresponding to validate rules
+// of a value of type tp to s.
+func jsonSchemaApplyRules(s jsonSchema, tp reflect.Type, rules []string) {
+	var patterns []string
+	for _, rule := range rules {
+		if strings.Contains(rule, "|") {
+			continue // Alternatives aren't supported.
+		}
+		name, param, _ := strings.Cut(rule, "=")
+		if f, ok := jsonSchemaValidateFormats[name]; ok {
+			s["format"] = f
+			continue
+		}
+		if p, ok := jsonSchemaValidatePatterns[name]; ok {
+			patterns = append(patterns, p)
+			continue
+		}
+		switch name {
+		case "required":
+			if tp.Kind() == reflect.String {
+				jsonSchemaSetBound(s, "minLength", 1, false)
+			}
+		case "min", "gte":
+			jsonSchemaApplyBound(s, tp, param, "minimum", "minLength", false)
+		case "max", "lte":
+			jsonSchemaApplyBound(s, tp, param, "maximum", "maxLength", true)
+		case "gt":
+			if isNumberKind(tp.Kind()) {
+				if n, err := strconv.ParseFloat(param, 64); err == nil {
+					s["exclusiveMinimum"] = jsonNumber(n)
+				}
+			}
+		case "lt":
+			if isNumberKind(tp.Kind()) {
+				if n, err := strconv.ParseFloat(param, 64); err == nil {
+					s["exclusiveMaximum"] = jsonNumber(n)
+				}
+			}
+		case "len":
+			jsonSchemaApplyBound(s, tp, param, "", "minLength", false)
+			jsonSchemaApplyBound(s, tp, param, "", "maxLength", true)
+		case "eq":
+			if v, ok := jsonSchemaEnumValue(tp, param); ok {
+				s["const"] = v
+			}
+		case "oneof":
+			var values []any
+			for _, p := range strings.Fields(param) {
+				v, ok := jsonSchemaEnumValue(tp, strings.Trim(p, "'"))
+				if !ok {
+					values = nil
+					break
+				}
+				values = append(values, v)
+			}
+			if values == nil {
+				continue
+			}
+			if _, ok := s["type"].([]string); ok {
+				values = append(values, nil) // Nullable
+			}
+			s["enum"] = values
+		case "contains":
+			patterns = append(patterns, regexp.QuoteMeta(param))
+		case "startswith":
+			patterns = append(patterns, "^"+regexp.QuoteMeta(param))
+		case "endswith":
+			patterns = append(patterns, regexp.QuoteMeta(param)+"$")
+		}
+	}
+	for i, p := range patterns {
+		if i == 0 {
+			s["pattern"] = p
+			continue
+		}
+		allOf, _ := s["allOf"].([]any)
+		s["allOf"] = append(allOf, jsonSchema{"pattern": p})
+	}
+}
+
+// jsonSchemaApplyBound sets the bound param as keyword numberKeyword for
+// numbers and the equivalent length keyword for strings, arrays and objects
+// derived from lengthKeyword ("minLength" or "maxLength").
+func jsonSchemaApplyBound(
+	s jsonSchema, tp reflect.Type, param, numberKeyword, lengthKeyword string,
+	upper bool,
+) {
+	if isNumberKind(tp.Kind()) {
+		if numberKeyword == "" {
+			return
+		}
+		if n, err := strconv.ParseFloat(param, 64); err == nil {
+			jsonSchemaSetBound(s, numberKeyword, jsonNumber(n), upper)
+		}
+		return
+	}
+	n, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return // Not a length (e.g. a duration bound).
+	}
+	switch tp.Kind() {
+	case reflect.String:
+	case reflect.Slice, reflect.Array:
+		lengthKeyword = strings.Replace(lengthKeyword, "Length", "Items", 1)
+	case reflect.Map:
+		lengthKeyword = strings.Replace(lengthKeyword, "Length", "Properties", 1)
+	default:
+		return
+	}
+	jsonSchemaSetBound(s, lengthKeyword, n, upper)
+}
+
+// jsonSchemaSetBound sets keyword to n unless the existing bound is tighter.
+func jsonSchemaSetBound(s jsonSchema, keyword string, n any, upper bool) {
+	if existing, ok := s[keyword]; ok {
+		e, v := toFloat64(existing), toFloat64(n)
+		if upper && e <= v || !upper && e >= v {
+			return
+		}
+	}
+	s[keyword] = n
+}
+
+// jsonSchemaEnumValue returns the JSON value of enum value param of type tp.
+func jsonSchemaEnumValue(tp reflect.Type, param string) (any, bool) {
+	switch {
+	case tp.Kind() == reflect.String:
+		return param, true
+	case isNumberKind(tp.Kind()):
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return nil, false
+		}
+		return jsonNumber(n), true
+	}
+	return nil, false
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// jsonNumber returns n as an int64 if it's a whole number.
+func jsonNumber(n float64) any {
+	if n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+		return int64(n)
+	}
+	return n
+}
+
+func toFloat64(v any) float64 {
+	switch v := v.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
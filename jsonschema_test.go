@@ -0,0 +1,229 @@
+package yamagiconf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+type SchemaConfig struct {
+	Name           string                    `yaml:"name" validate:"required,alphanum,max=16"`
+	Mode           string                    `yaml:"mode" validate:"oneof=dev prod"`
+	Level          *uint8                    `yaml:"level" validate:"omitempty,min=1,max=5"`
+	Offset         int16                     `yaml:"offset" validate:"gt=-10,lt=10"`
+	Count          uint64                    `yaml:"count"`
+	Ratio          float32                   `yaml:"ratio" validate:"gte=0,lte=1"`
+	Timeout        time.Duration             `yaml:"timeout" default:"30s" validate:"min=1s"`
+	Email          string                    `yaml:"email" validate:"email,endswith=.com"`
+	Tags           []string                  `yaml:"tags" validate:"min=1,dive,alpha"`
+	Pair           [2]bool                   `yaml:"pair"`
+	Limits         map[int32]float64         `yaml:"limits"`
+	Text           ValidatedString           `yaml:"text"`
+	Password       yamagiconf.Secret[string] `yaml:"password"`
+	Ignored        string                    `yaml:"-"`
+	Server         *SchemaServer             `yaml:"server"`
+	SchemaEmbedded `yaml:",inline"`
+}
+
+type SchemaServer struct {
+	Host string `yaml:"host" validate:"hostname" doc:"Host name."`
+}
+
+type SchemaEmbedded struct {
+	Region string `yaml:"region" validate:"startswith=eu-"`
+}
+
+func TestJSONSchema(t *testing.T) {
+	s, err := yamagiconf.JSONSchema[SchemaConfig]()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "SchemaConfig",
+  "type": "object",
+  "additionalProperties": false,
+  "required": [
+    "name", "mode", "level", "offset", "count", "ratio", "email", "tags",
+    "pair", "limits", "text", "password", "server", "region"
+  ],
+  "properties": {
+    "name": {
+      "type": "string", "minLength": 1, "maxLength": 16,
+      "pattern": "^[a-zA-Z0-9]+$"
+    },
+    "mode": {"type": "string", "enum": ["dev", "prod"]},
+    "level": {"type": ["integer", "null"], "minimum": 1, "maximum": 5},
+    "offset": {
+      "type": "integer", "minimum": -32768, "maximum": 32767,
+      "exclusiveMinimum": -10, "exclusiveMaximum": 10
+    },
+    "count": {"type": "integer", "minimum": 0, "maximum": 18446744073709551615},
+    "ratio": {"type": "number", "minimum": 0, "maximum": 1},
+    "timeout": {"type": "string", "format": "duration", "default": "30s"},
+    "email": {"type": "string", "format": "email", "pattern": "\\.com$"},
+    "tags": {"type": ["array", "null"], "items": {"type": "string"}, "minItems": 1},
+    "pair": {
+      "type": "array", "items": {"type": "boolean"}, "minItems": 2, "maxItems": 2
+    },
+    "limits": {
+      "type": ["object", "null"],
+      "propertyNames": {"pattern": "^-?[0-9]+$"},
+      "additionalProperties": {"type": "number"}
+    },
+    "text": {"type": "string"},
+    "password": {"type": "string"},
+    "server": {
+      "type": ["object", "null"],
+      "additionalProperties": false,
+      "required": ["host"],
+      "properties": {
+        "host": {"type": "string", "format": "hostname", "description": "Host name."}
+      }
+    },
+    "region": {"type": "string", "pattern": "^eu-"}
+  }
+}`, string(s))
+}
+
+func TestJSONSchemaInlineMap(t *testing.T) {
+	type Extra map[string]uint8
+	type TestConfig struct {
+		Name  string `yaml:"name"`
+		Extra `yaml:",inline"`
+	}
+	s, err := yamagiconf.JSONSchema[TestConfig]()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "TestConfig",
+  "type": "object",
+  "additionalProperties": {"type": "integer", "minimum": 0, "maximum": 255},
+  "required": ["name"],
+  "properties": {"name": {"type": "string"}}
+}`, string(s))
+}
+
+func TestJSONSchemaOmitEmpty(t *testing.T) {
+	type TestConfig struct {
+		Nick   string            `yaml:"nick" validate:"omitempty,min=3"`
+		Port   uint16            `yaml:"port" validate:"omitempty,min=1024"`
+		Tags   []string          `yaml:"tags" validate:"omitempty,min=2,dive,alpha"`
+		Labels map[string]string `yaml:"labels" validate:"omitempty,max=2"`
+		Alias  *string           `yaml:"alias" validate:"omitempty,min=3"`
+		Pair   [2]int8           `yaml:"pair" validate:"omitempty,max=1"`
+	}
+	s, err := yamagiconf.JSONSchema[TestConfig]()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "TestConfig",
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["nick", "port", "tags", "labels", "alias", "pair"],
+  "properties": {
+    "nick": {
+      "type": "string",
+      "anyOf": [{"const": ""}, {"minLength": 3}]
+    },
+    "port": {
+      "type": "integer", "minimum": 0, "maximum": 65535,
+      "anyOf": [{"const": 0}, {"minimum": 1024}]
+    },
+    "tags": {
+      "type": ["array", "null"], "items": {"type": "string"}, "minItems": 2
+    },
+    "labels": {
+      "type": ["object", "null"], "additionalProperties": {"type": "string"},
+      "maxProperties": 2
+    },
+    "alias": {"type": ["string", "null"], "minLength": 3},
+    "pair": {
+      "type": "array", "items": {"type": "integer", "minimum": -128, "maximum": 127},
+      "minItems": 2, "maxItems": 2
+    }
+  }
+}`, string(s))
+
+	// Load accepts the empty values the schema accepts.
+	_, err = LoadSrc[TestConfig](`nick: ""
+port: 0
+tags: null
+labels: null
+alias: null
+pair: [0, 0]
+`)
+	require.NoError(t, err)
+}
+
+func TestJSONSchemaRequiredNilable(t *testing.T) {
+	type TestConfig struct {
+		Proxy  *string           `yaml:"proxy" validate:"required"`
+		Tags   []string          `yaml:"tags" validate:"required,dive,min=1"`
+		Labels map[string]string `yaml:"labels" validate:"required"`
+		Mode   *string           `yaml:"mode" validate:"required,oneof=a b"`
+		Items  []*string         `yaml:"items" validate:"dive,required"`
+	}
+	s, err := yamagiconf.JSONSchema[TestConfig]()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "TestConfig",
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["proxy", "tags", "labels", "mode", "items"],
+  "properties": {
+    "proxy": {"type": "string"},
+    "tags": {"type": "array", "items": {"type": "string"}},
+    "labels": {"type": "object", "additionalProperties": {"type": "string"}},
+    "mode": {"type": "string", "enum": ["a", "b"]},
+    "items": {"type": ["array", "null"], "items": {"type": ["string", "null"]}}
+  }
+}`, string(s))
+
+	_, err = LoadSrc[TestConfig]("proxy: null\ntags: [a]\nlabels: {}\n" +
+		"mode: a\nitems: []\n")
+	require.ErrorIs(t, err, yamagiconf.ErrValidationTag)
+
+	// Required pointers may point to empty values.
+	_, err = LoadSrc[TestConfig]("proxy: \"\"\ntags: [a]\nlabels: {}\n" +
+		"mode: a\nitems: []\n")
+	require.NoError(t, err)
+}
+
+func TestJSONSchemaEnvOnlySecret(t *testing.T) {
+	type TestConfig struct {
+		Token    yamagiconf.Secret[string]  `yaml:"token" env:"SCHEMA_TEST_TOKEN" secret:"env-only" validate:"required"`
+		Password *string                    `yaml:"password" env:"SCHEMA_TEST_PASSWORD" secret:"env-only"`
+		Key      yamagiconf.Secret[*string] `yaml:"key" env:"SCHEMA_TEST_KEY" secret:"env-only"`
+	}
+	s, err := yamagiconf.JSONSchema[TestConfig]()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "TestConfig",
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["token", "password", "key"],
+  "properties": {
+    "token": {"type": "string", "maxLength": 0},
+    "password": {"type": "null"},
+    "key": {"type": "null"}
+  }
+}`, string(s))
+
+	t.Setenv("SCHEMA_TEST_TOKEN", "t")
+	_, err = LoadSrc[TestConfig]("token: \"\"\npassword: null\nkey: null\n")
+	require.NoError(t, err)
+	_, err = LoadSrc[TestConfig]("token: x\npassword: null\nkey: null\n")
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLPlaintextSecret)
+}
+
+func TestJSONSchemaErr(t *testing.T) {
+	type TestConfig struct {
+		Port int `yaml:"port"`
+	}
+	_, err := yamagiconf.JSONSchema[TestConfig]()
+	require.ErrorIs(t, err, yamagiconf.ErrTypeUnsupported)
+}
@@ -0,0 +1,49 @@
+package yamagiconf
+
+import (
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// validateYAMLKeyOrder returns an error if the keys of mapping node
+// don't appear in the order of the fields of struct type tp.
+// Keys of values in defaults and keys of embedded inline maps are ignored.
+func validateYAMLKeyOrder(
+	defaults map[*yaml.Node]struct{}, tp reflect.Type, node *yaml.Node,
+) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	order := getStructInfo(tp).Order
+	index := make(map[string]int, len(order))
+	for i, k := range order {
+		index[k] = i
+	}
+	present := make([]bool, len(order))
+	last := -1
+	for i := 0; i < len(node.Content); i += 2 {
+		if _, ok := defaults[node.Content[i+1]]; ok {
+			continue
+		}
+		k := node.Content[i]
+		x, ok := index[k.Value]
+		if !ok {
+			continue // Key of an embedded inline map.
+		}
+		if x < last {
+			// Find the key that is expected right before k.
+			for p := x - 1; p >= 0; p-- {
+				if present[p] {
+					return fmt.Errorf("at %d:%d: %w: %q must follow %q",
+						k.Line, k.Column, ErrYAMLKeyOrder, k.Value, order[p])
+				}
+			}
+			return fmt.Errorf("at %d:%d: %w: %q must be the first key",
+				k.Line, k.Column, ErrYAMLKeyOrder, k.Value)
+		}
+		present[x], last = true, x
+	}
+	return nil
+}
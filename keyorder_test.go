@@ -0,0 +1,139 @@
+package yamagiconf_test
+
+import (
+	"testing"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+type KeyOrderEmbedded struct {
+	B string `yaml:"b"`
+	C string `yaml:"c"`
+}
+
+type KeyOrderConfig struct {
+	A                string `yaml:"a"`
+	KeyOrderEmbedded `yaml:",inline"`
+	D                *KeyOrderNested `yaml:"d"`
+	E                string          `yaml:"e" default:"e"`
+	F                string          `yaml:"f"`
+}
+
+type KeyOrderNested struct {
+	X int32 `yaml:"x"`
+	Y int32 `yaml:"y" default:"2"`
+	Z int32 `yaml:"z"`
+}
+
+func TestKeyOrder(t *testing.T) {
+	load := func(t *testing.T, src string) error {
+		t.Helper()
+		var c KeyOrderConfig
+		return yamagiconf.Load(src, &c, yamagiconf.WithStrictKeyOrder())
+	}
+
+	t.Run("ordered", func(t *testing.T) {
+		require.NoError(t, load(t, `
+a: a
+b: b
+c: c
+d:
+  x: 1
+  y: 2
+  z: 3
+e: e
+f: f
+`))
+	})
+
+	t.Run("defaults omitted", func(t *testing.T) {
+		require.NoError(t, load(t, `
+a: a
+b: b
+c: c
+d:
+  x: 1
+  z: 3
+f: f
+`))
+	})
+
+	t.Run("unordered without option", func(t *testing.T) {
+		var c KeyOrderConfig
+		require.NoError(t, yamagiconf.Load(`
+f: f
+a: a
+c: c
+b: b
+d: null
+`, &c))
+	})
+
+	t.Run("out of order", func(t *testing.T) {
+		err := load(t, `
+a: a
+c: c
+b: b
+d: null
+f: f
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLKeyOrder)
+		require.Equal(t, `at 4:1: keys must appear in the order of the struct `+
+			`fields: "b" must follow "a"`, err.Error())
+	})
+
+	t.Run("first key", func(t *testing.T) {
+		err := load(t, `
+b: b
+a: a
+c: c
+d: null
+f: f
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLKeyOrder)
+		require.Equal(t, `at 3:1: keys must appear in the order of the struct `+
+			`fields: "a" must be the first key`, err.Error())
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		err := load(t, `
+a: a
+b: b
+c: c
+d:
+  z: 3
+  x: 1
+f: f
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLKeyOrder)
+		require.Equal(t, `at 7:3: keys must appear in the order of the struct `+
+			`fields: "x" must be the first key`, err.Error())
+	})
+
+	t.Run("default tag", func(t *testing.T) {
+		type Config struct {
+			N KeyOrderNested `yaml:"n" default:"{z: 3, x: 1}"`
+		}
+		err := yamagiconf.ValidateType[Config](yamagiconf.WithStrictKeyOrder())
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidDefaultTag)
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLKeyOrder)
+		require.NoError(t, yamagiconf.ValidateType[Config]())
+	})
+}
+
+func TestKeyOrderFormat(t *testing.T) {
+	formatted, err := yamagiconf.Format[KeyOrderConfig]([]byte(`
+f: f
+d:
+  z: 3
+  x: 1
+c: c
+b: b
+a: a
+`))
+	require.NoError(t, err)
+	var c KeyOrderConfig
+	require.NoError(t, yamagiconf.Load(formatted, &c, yamagiconf.WithStrictKeyOrder()))
+}
@@ -0,0 +1,183 @@
+package yamagiconf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// Option configures a Loader and the package-level functions
+// LoadFile, Load, LoadStream and Validate.
+type Option func(*options)
+
+// WithValidator makes yamagiconf use v for go-playground/validator struct tags
+// instead of the default validator.
+// Use it to register custom validations, struct validations, aliases or
+// a custom tag name. Failures of custom validations are reported with their
+// line and column in the YAML document just like those of built-in tags.
+// v must not be modified after it was passed to WithValidator.
+func WithValidator(v *validator.Validate) Option {
+	return func(o *options) { o.validate = v }
+}
+
+// WithTranslator makes validation errors include a human-readable message
+// translated by trans, which allows reporting errors in any locale.
+// WithTranslator requires WithValidator and the translations must be
+// registered on the validator provided with it, for example using package
+// github.com/go-playground/validator/v10/translations/en.
+// Without WithValidator, functions taking the option return
+// ErrTranslatorWithoutValidator.
+func WithTranslator(trans ut.Translator) Option {
+	return func(o *options) { o.translator = trans }
+}
+
+// WithStrictKeyOrder makes the loader require the keys of mappings of
+// struct types to appear in the order of the struct fields, where the fields
+// of embedded inline structs take the place of the embedded field.
+// Keys of embedded inline maps may appear anywhere and keys that are missing
+// because of a `default` struct tag are not considered.
+// Format writes keys in the required order.
+func WithStrictKeyOrder() Option {
+	return func(o *options) { o.strictKeyOrder = true }
+}
+
+// WithYAMLTagConvention makes ValidateType require all yaml struct tags
+// to follow naming convention c.
+func WithYAMLTagConvention(c NamingConvention) Option {
+	return func(o *options) { o.yamlTagConvention = c }
+}
+
+// WithMapKeyConvention makes the loader require the keys of maps with
+// string keys to follow naming convention c. Maps with keys of other types,
+// including types implementing encoding.TextUnmarshaler, are not affected.
+func WithMapKeyConvention(c NamingConvention) Option {
+	return func(o *options) { o.mapKeyConvention = c }
+}
+
+type options struct {
+	validate   *validator.Validate
+	translator ut.Translator
+	rules      Rules
+
+	// The rules set by dedicated options override those of WithRules
+	// regardless of the order of the options.
+	strictKeyOrder    bool
+	yamlTagConvention NamingConvention
+	mapKeyConvention  NamingConvention
+}
+
+func newOptions(opts []Option) *options {
+	o := new(options)
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.strictKeyOrder {
+		o.rules.StrictKeyOrder = true
+	}
+	if o.yamlTagConvention != 0 {
+		o.rules.YAMLTagConvention = o.yamlTagConvention
+	}
+	if o.mapKeyConvention != 0 {
+		o.rules.MapKeyConvention = o.mapKeyConvention
+	}
+	if o.validate == nil {
+		o.validate = defaultValidator()
+	}
+	return o
+}
+
+// defaultValidator is shared because constructing a validator is expensive
+// and it caches struct metadata internally. It's safe for concurrent use.
+var defaultValidator = sync.OnceValue(func() *validator.Validate {
+	return validator.New(validator.WithRequiredStructEnabled())
+})
+
+// Loader loads and validates configurations of type T.
+// Unlike the package-level functions, a Loader validates type T only once
+// when it's created and reuses its validator across all calls, which makes
+// it the better choice for repeated loading (hot-reload, tests, etc.).
+// A Loader is safe for concurrent use.
+type Loader[T any] struct{ opts *options }
+
+// NewLoader returns a new Loader for type T.
+// Returns the error of ValidateType if T is invalid.
+func NewLoader[T any](opts ...Option) (*Loader[T], error) {
+	o := newOptions(opts)
+	if err := validateType[T](o); err != nil {
+		return nil, err
+	}
+	return &Loader[T]{opts: o}, nil
+}
+
+// LoadFile behaves like the package-level LoadFile.
+func (l *Loader[T]) LoadFile(yamlFilePath string, config *T) error {
+	return l.LoadFileContext(context.Background(), yamlFilePath, config)
+}
+
+// LoadFileContext behaves like the package-level LoadFileContext.
+func (l *Loader[T]) LoadFileContext(
+	ctx context.Context, yamlFilePath string, config *T,
+) error {
+	if config == nil {
+		return ErrConfigNil
+	}
+
+	yamlSrcBytes, err := os.ReadFile(yamlFilePath)
+	if err != nil {
+		return fmt.Errorf("reading file %q: %w", yamlFilePath, err)
+	}
+	return load(ctx, l.opts, yamlSrcBytes, config, yamlFilePath, nil)
+}
+
+// Load behaves like the package-level Load.
+func (l *Loader[T]) Load(yamlSource []byte, config *T) error {
+	return load(context.Background(), l.opts, yamlSource, config, "", nil)
+}
+
+// LoadContext behaves like the package-level LoadContext.
+func (l *Loader[T]) LoadContext(
+	ctx context.Context, yamlSource []byte, config *T,
+) error {
+	return load(ctx, l.opts, yamlSource, config, "", nil)
+}
+
+// LoadFileWithProvenance behaves like the package-level LoadFileWithProvenance.
+func (l *Loader[T]) LoadFileWithProvenance(
+	yamlFilePath string, config *T,
+) (Provenance, error) {
+	return loadFileWithProvenance(l.opts, yamlFilePath, config)
+}
+
+// LoadWithProvenance behaves like the package-level LoadWithProvenance.
+func (l *Loader[T]) LoadWithProvenance(
+	yamlSource []byte, config *T,
+) (Provenance, error) {
+	if config == nil {
+		return nil, ErrConfigNil
+	}
+	prov := Provenance{}
+	err := load(context.Background(), l.opts, yamlSource, config, "", prov)
+	if err != nil {
+		return nil, err
+	}
+	return prov, nil
+}
+
+// LoadStream behaves like the package-level LoadStream.
+func (l *Loader[T]) LoadStream(yamlSource []byte) ([]T, error) {
+	return loadStream[T](l.opts, yamlSource)
+}
+
+// SetValue behaves like the package-level SetValue.
+func (l *Loader[T]) SetValue(src []byte, yamlPath string, value any) ([]byte, error) {
+	return setValue[T](l.opts, src, yamlPath, value)
+}
+
+// Validate behaves like the package-level Validate.
+func (l *Loader[T]) Validate(t T) error {
+	return validate(l.opts, t)
+}
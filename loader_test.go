@@ -0,0 +1,175 @@
+package yamagiconf_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+type LoaderTestServer struct {
+	Host    string        `yaml:"host" validate:"required"`
+	Port    uint16        `yaml:"port" validate:"required"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type LoaderTestConfig struct {
+	Name    string                      `yaml:"name" env:"LOADER_TEST_NAME" validate:"required"`
+	Servers []LoaderTestServer          `yaml:"servers"`
+	Labels  map[string]string           `yaml:"labels"`
+	Limits  map[string]*LoaderTestLimit `yaml:"limits"`
+	Debug   bool                        `yaml:"debug"`
+}
+
+type LoaderTestLimit struct {
+	Max ValidatedString `yaml:"max"`
+}
+
+const loaderTestSrc = `name: test
+servers:
+  - host: first.local
+    port: 8080
+    timeout: 5s
+  - host: second.local
+    port: 8081
+    timeout: 10s
+labels:
+  env: prod
+  team: platform
+limits:
+  foo:
+    max: valid
+  bar: null
+debug: false
+`
+
+func TestLoader(t *testing.T) {
+	l, err := yamagiconf.NewLoader[LoaderTestConfig]()
+	require.NoError(t, err)
+
+	expect := LoaderTestConfig{
+		Name: "test",
+		Servers: []LoaderTestServer{
+			{Host: "first.local", Port: 8080, Timeout: 5 * time.Second},
+			{Host: "second.local", Port: 8081, Timeout: 10 * time.Second},
+		},
+		Labels: map[string]string{"env": "prod", "team": "platform"},
+		Limits: map[string]*LoaderTestLimit{"foo": {Max: "valid"}, "bar": nil},
+	}
+
+	t.Run("load", func(t *testing.T) {
+		var c LoaderTestConfig
+		require.NoError(t, l.Load([]byte(loaderTestSrc), &c))
+		require.Equal(t, expect, c)
+		require.NoError(t, l.Validate(c))
+	})
+
+	t.Run("load_file", func(t *testing.T) {
+		p := filepath.Join(t.TempDir(), "test-config.yaml")
+		require.NoError(t, os.WriteFile(p, []byte(loaderTestSrc), 0o664))
+		var c LoaderTestConfig
+		require.NoError(t, l.LoadFile(p, &c))
+		require.Equal(t, expect, c)
+	})
+
+	t.Run("load_stream", func(t *testing.T) {
+		c, err := l.LoadStream([]byte(loaderTestSrc + "---\n" + loaderTestSrc))
+		require.NoError(t, err)
+		require.Equal(t, []LoaderTestConfig{expect, expect}, c)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("LOADER_TEST_NAME", "from-env")
+		var c LoaderTestConfig
+		require.NoError(t, l.Load([]byte(loaderTestSrc), &c))
+		require.Equal(t, "from-env", c.Name)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var c LoaderTestConfig
+				if err := l.Load([]byte(loaderTestSrc), &c); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("err_nil_config", func(t *testing.T) {
+		require.ErrorIs(t, l.Load([]byte(loaderTestSrc), nil), yamagiconf.ErrConfigNil)
+		require.ErrorIs(t, l.LoadFile("config.yaml", nil), yamagiconf.ErrConfigNil)
+	})
+
+	t.Run("err_empty", func(t *testing.T) {
+		var c LoaderTestConfig
+		require.ErrorIs(t, l.Load(nil, &c), yamagiconf.ErrYAMLEmptyFile)
+	})
+
+	t.Run("err_validation", func(t *testing.T) {
+		var c LoaderTestConfig
+		err := l.Load([]byte(`name: ''
+servers:
+  - host: first.local
+    port: 8080
+    timeout: 5s
+labels: null
+limits: null
+debug: false
+`), &c)
+		require.ErrorIs(t, err, yamagiconf.ErrValidationTag)
+		require.Equal(t,
+			`at 1:7: "name" violates validation rule: "required"`, err.Error())
+	})
+
+	t.Run("err_unknown_field", func(t *testing.T) {
+		var c LoaderTestConfig
+		err := l.Load([]byte(loaderTestSrc+"unknown: field\n"), &c)
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLMalformed)
+		require.Equal(t, `at 17:1: malformed YAML: field "unknown" not found `+
+			`in type yamagiconf_test.LoaderTestConfig`, err.Error())
+	})
+}
+
+func TestNewLoaderErrType(t *testing.T) {
+	type TestConfig struct {
+		Int int `yaml:"int"`
+	}
+	l, err := yamagiconf.NewLoader[TestConfig]()
+	require.ErrorIs(t, err, yamagiconf.ErrTypeUnsupported)
+	require.Nil(t, l)
+}
+
+func BenchmarkLoad(b *testing.B) {
+	src := []byte(loaderTestSrc)
+	for range b.N {
+		var c LoaderTestConfig
+		if err := yamagiconf.Load(src, &c); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoader(b *testing.B) {
+	src := []byte(loaderTestSrc)
+	l, err := yamagiconf.NewLoader[LoaderTestConfig]()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for range b.N {
+		var c LoaderTestConfig
+		if err := l.Load(src, &c); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package yamagiconf
+
+import (
+	"encoding"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// LogValueMaxItems is the maximum number of slice, array and map items
+// included in the output of LogValue per collection.
+// Further items are summarized by a single attribute with key "..." stating
+// the number of omitted items.
+const LogValueMaxItems = 32
+
+// LogValue returns cfg as a group value for log/slog.
+// Attributes are keyed by the YAML field names, fields tagged `yaml:"-"` are
+// omitted, fields of embedded inline structs and maps are flattened into
+// the group of their parent and the values of secret fields (see Secret and
+// the `secret` struct tag) are replaced by "[REDACTED]".
+// Slices and arrays are groups keyed by item index.
+// At most LogValueMaxItems items are included per collection.
+//
+// LogValue returns a group value with no attributes if cfg == nil.
+func LogValue[T any](cfg *T) slog.Value {
+	if cfg == nil {
+		return slog.GroupValue()
+	}
+	return logValue(reflect.ValueOf(cfg).Elem())
+}
+
+func logValue(v reflect.Value) slog.Value {
+	tp := v.Type()
+	switch tp.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		if v.IsNil() {
+			return slog.AnyValue(nil)
+		}
+	}
+	if _, ok := secretValueType(tp); ok {
+		return slog.StringValue(Redacted)
+	}
+	if m := asIface[encoding.TextMarshaler](v, false); m != nil {
+		text, err := m.MarshalText()
+		if err != nil {
+			return slog.StringValue("!ERROR: " + err.Error())
+		}
+		return slog.StringValue(string(text))
+	}
+	if tp == typeTimeDuration {
+		return slog.DurationValue(time.Duration(v.Int()))
+	}
+
+	switch tp.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return logValue(v.Elem())
+	case reflect.Bool:
+		return slog.BoolValue(v.Bool())
+	case reflect.String:
+		return slog.StringValue(v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return slog.Int64Value(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return slog.Uint64Value(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return slog.Float64Value(v.Float())
+	case reflect.Struct:
+		if !isPlainStruct(tp) {
+			break
+		}
+		return slog.GroupValue(logAttrsFields(v, nil)...)
+	case reflect.Slice, reflect.Array:
+		n := min(v.Len(), LogValueMaxItems)
+		attrs := make([]slog.Attr, 0, n+1)
+		for i := range n {
+			attrs = append(attrs, slog.Attr{
+				Key: strconv.Itoa(i), Value: logValue(v.Index(i)),
+			})
+		}
+		return slog.GroupValue(appendOmitted(attrs, v.Len()-n)...)
+	case reflect.Map:
+		return slog.GroupValue(logAttrsMap(v, nil)...)
+	}
+	return slog.AnyValue(v.Interface())
+}
+
+// logAttrsFields appends the attributes of the fields of struct value v
+// including the fields of embedded inline structs and maps to attrs.
+func logAttrsFields(v reflect.Value, attrs []slog.Attr) []slog.Attr {
+	for _, f := range getStructInfo(v.Type()).Fields {
+		fv := v.FieldByIndex(f.Index)
+		switch {
+		case f.YAMLTag == "-":
+			continue
+		case f.Anonymous:
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Map {
+				attrs = logAttrsMap(fv, attrs)
+				continue
+			}
+			attrs = logAttrsFields(fv, attrs)
+			continue
+		}
+		value := slog.StringValue(Redacted)
+		if !isSecretField(f.StructField) {
+			value = logValue(fv)
+		}
+		attrs = append(attrs, slog.Attr{Key: f.YAMLTag, Value: value})
+	}
+	return attrs
+}
+
+// logAttrsMap appends the attributes of the first LogValueMaxItems
+// entries of map m in key order to attrs.
+func logAttrsMap(m reflect.Value, attrs []slog.Attr) []slog.Attr {
+	if m.IsNil() {
+		return attrs
+	}
+	keys := mapKeysSorted(m)
+	n := min(len(keys), LogValueMaxItems)
+	for _, k := range keys[:n] {
+		key := fmt.Sprint(k.Interface())
+		if t := asIface[encoding.TextMarshaler](k, false); t != nil {
+			if text, err := t.MarshalText(); err == nil {
+				key = string(text)
+			}
+		}
+		attrs = append(attrs, slog.Attr{Key: key, Value: logValue(m.MapIndex(k))})
+	}
+	return appendOmitted(attrs, len(keys)-n)
+}
+
+func appendOmitted(attrs []slog.Attr, omitted int) []slog.Attr {
+	if omitted < 1 {
+		return attrs
+	}
+	return append(attrs, slog.String("...", fmt.Sprintf("%d more", omitted)))
+}
@@ -0,0 +1,73 @@
+package yamagiconf_test
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogValue(t *testing.T) {
+	var c DumpConfig
+	require.NoError(t, yamagiconf.Load(dumpTestSrc, &c))
+	c.Ignored = "ignored"
+
+	var b bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	l.Info("config", "cfg", yamagiconf.LogValue(&c))
+	require.Equal(t, `level=INFO msg=config cfg.name=test cfg.quoted=true `+
+		`cfg.debug=false cfg.timeout=30s cfg.port=8080 cfg.ratio=0.5 `+
+		`cfg.password=[REDACTED] cfg.proxy=<nil> cfg.tags.0=a cfg.tags.1=b `+
+		`cfg.labels.a=first cfg.labels.z=last `+
+		`cfg.servers.0.host=a.local cfg.servers.0.tls=true cfg.text=valid `+
+		`cfg.limits.a=<nil> cfg.limits.b=2 cfg.region=eu`+"\n", b.String())
+
+	require.Equal(t, slog.KindGroup, yamagiconf.LogValue[DumpConfig](nil).Kind())
+}
+
+func TestLogValueSecret(t *testing.T) {
+	var c SecretConfig
+	require.NoError(t, yamagiconf.Load("password: hunter2\npin: 1234\n"+
+		"token: null\nenabled: true\n", &c))
+	v := yamagiconf.LogValue(&c)
+	require.NotContains(t, v.String(), "hunter2")
+	require.NotContains(t, v.String(), "1234")
+	require.Equal(t, "[REDACTED]", v.Group()[0].Value.String())
+}
+
+func TestLogValueBounded(t *testing.T) {
+	type TestConfig struct {
+		Items []string          `yaml:"items"`
+		Map   map[string]uint32 `yaml:"map"`
+	}
+	c := TestConfig{Map: map[string]uint32{}}
+	for i := range yamagiconf.LogValueMaxItems + 10 {
+		c.Items = append(c.Items, fmt.Sprintf("item%d", i))
+		c.Map[fmt.Sprintf("k%03d", i)] = uint32(i)
+	}
+	g := yamagiconf.LogValue(&c).Group()
+	require.Len(t, g, 2)
+
+	items := g[0].Value.Group()
+	require.Len(t, items, yamagiconf.LogValueMaxItems+1)
+	require.Equal(t, "item0", items[0].Value.String())
+	require.Equal(t, slog.String("...", "10 more"), items[len(items)-1])
+
+	m := g[1].Value.Group()
+	require.Len(t, m, yamagiconf.LogValueMaxItems+1)
+	require.Equal(t, "k000", m[0].Key)
+	require.Equal(t, slog.String("...", "10 more"), m[len(m)-1])
+	require.False(t, strings.Contains(yamagiconf.LogValue(&c).String(), "item41"))
+}
@@ -0,0 +1,44 @@
+package yamagiconf
+
+import (
+	"bytes"
+	"reflect"
+)
+
+// Marshal encodes cfg as YAML that Load decodes back into a value equal to
+// cfg given that cfg passes validation:
+//   - fields are written in the order of the struct and every field
+//     is written, including fields with a `default` struct tag.
+//   - booleans are written as either `true` or `false`.
+//   - nil pointers, slices and maps are written as `null`, `null` is never
+//     used for other types.
+//   - strings that would otherwise be read as null, a boolean or a number
+//     are quoted.
+//   - encoding.TextMarshaler is used for types that implement it.
+//   - anchors, aliases and YAML tags are never used.
+//
+// Unlike DumpEffective, Marshal writes the values of secrets except for
+// fields tagged `secret:"env-only"`, which are written as null or an empty
+// value because LoadFile doesn't accept them in the YAML file.
+// Returns an error if cfg contains strings that aren't valid UTF-8.
+// opts only affect the validation of T (see ValidateType), the output is
+// the same for all options. Fields are written in the order of the struct as
+// required by Rules.StrictKeyOrder but map keys are written as they are and
+// fail Load if they violate Rules.MapKeyConvention.
+func Marshal[T any](cfg *T, opts ...Option) ([]byte, error) {
+	if cfg == nil {
+		return nil, ErrConfigNil
+	}
+	if err := validateType[T](newOptions(opts)); err != nil {
+		return nil, err
+	}
+	node, err := nodeEncoder{}.encode("", reflect.ValueOf(cfg).Elem())
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := encodeYAML(&b, node); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,163 @@
+package yamagiconf_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+type MarshalConfig struct {
+	Str             string                               `yaml:"str"`
+	Bool            bool                                 `yaml:"bool"`
+	Int             int64                                `yaml:"int"`
+	Uint            uint32                               `yaml:"uint"`
+	Float           float64                              `yaml:"float"`
+	Duration        time.Duration                        `yaml:"duration" default:"5s"`
+	Ptr             *string                              `yaml:"ptr"`
+	Strs            []string                             `yaml:"strs"`
+	Ptrs            []*int8                              `yaml:"ptrs"`
+	Map             map[string]float32                   `yaml:"map"`
+	Time            time.Time                            `yaml:"time"`
+	Password        yamagiconf.Secret[string]            `yaml:"password"`
+	Secrets         map[string]yamagiconf.Secret[string] `yaml:"secrets"`
+	Tagged          string                               `yaml:"tagged" secret:"true"`
+	Nested          *MarshalNested                       `yaml:"nested"`
+	Array           [2]MarshalNested                     `yaml:"array"`
+	MarshalEmbedded `yaml:",inline"`
+}
+
+type MarshalNested struct {
+	Name string `yaml:"name"`
+}
+
+type MarshalEmbedded struct {
+	Region string `yaml:"region"`
+}
+
+func TestMarshal(t *testing.T) {
+	c := MarshalConfig{
+		Str:             "true",
+		Bool:            true,
+		Int:             -42,
+		Uint:            42,
+		Float:           0.5,
+		Duration:        90 * time.Second,
+		Strs:            []string{"null", "1.5", "~", "yes", ""},
+		Ptrs:            []*int8{PtrTo(int8(1)), nil},
+		Map:             map[string]float32{"b": 2, "a": 1},
+		Time:            time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Password:        yamagiconf.NewSecret("hunter2"),
+		Secrets:         map[string]yamagiconf.Secret[string]{"k": yamagiconf.NewSecret("v")},
+		Tagged:          "tagged",
+		Nested:          &MarshalNested{Name: "n"},
+		MarshalEmbedded: MarshalEmbedded{Region: "eu"},
+	}
+	b, err := yamagiconf.Marshal(&c)
+	require.NoError(t, err)
+	require.Equal(t, `str: "true"
+bool: true
+int: -42
+uint: 42
+float: 0.5
+duration: 1m30s
+ptr: null
+strs:
+  - "null"
+  - "1.5"
+  - "~"
+  - "yes"
+  - ""
+ptrs:
+  - 1
+  - null
+map:
+  a: 1
+  b: 2
+time: "2024-01-02T03:04:05Z"
+password: hunter2
+secrets:
+  k: v
+tagged: tagged
+nested:
+  name: "n"
+array:
+  - name: ""
+  - name: ""
+region: eu
+`, string(b))
+
+	var loaded MarshalConfig
+	require.NoError(t, yamagiconf.Load(b, &loaded))
+	require.Equal(t, c, loaded)
+}
+
+func TestMarshalEnvOnlySecret(t *testing.T) {
+	type TestConfig struct {
+		Token string  `yaml:"token" env:"MARSHAL_TEST_TOKEN" secret:"env-only"`
+		Key   *string `yaml:"key" env:"MARSHAL_TEST_KEY" secret:"env-only"`
+	}
+	b, err := yamagiconf.Marshal(&TestConfig{
+		Token: "hunter2", Key: PtrTo("hunter2"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "token: \"\"\nkey: null\n", string(b))
+}
+
+func TestMarshalErr(t *testing.T) {
+	_, err := yamagiconf.Marshal[MarshalConfig](nil)
+	require.ErrorIs(t, err, yamagiconf.ErrConfigNil)
+
+	_, err = yamagiconf.Marshal(&MarshalConfig{Str: "\xff"})
+	require.Error(t, err)
+	require.Equal(t, "at str: string is not valid UTF-8", err.Error())
+
+	type TestConfig struct {
+		Port int `yaml:"port"`
+	}
+	_, err = yamagiconf.Marshal(&TestConfig{})
+	require.ErrorIs(t, err, yamagiconf.ErrTypeUnsupported)
+}
+
+func FuzzMarshal(f *testing.F) {
+	f.Add("text", true, int64(1), uint32(2), 1.5, int64(time.Second), "key", "")
+	f.Add("null", false, int64(-1), uint32(0), 0.0, int64(0), "true", "~")
+	f.Add("", false, int64(math.MinInt64), uint32(math.MaxUint32),
+		math.Inf(1), int64(-1), "", "- x")
+	f.Add("0x1F", true, int64(0), uint32(0), -0.0, int64(1), "1e3", "a: b")
+	f.Fuzz(func(
+		t *testing.T, s string, b bool, i int64, u uint32, fl float64,
+		d int64, k, p string,
+	) {
+		if math.IsNaN(fl) {
+			t.Skip("NaN is never equal to itself")
+		}
+		c := MarshalConfig{
+			Str:             s,
+			Bool:            b,
+			Int:             i,
+			Uint:            u,
+			Float:           fl,
+			Duration:        time.Duration(d),
+			Ptr:             &p,
+			Strs:            []string{s, k, p},
+			Ptrs:            []*int8{nil},
+			Map:             map[string]float32{k: float32(fl)},
+			Time:            time.Unix(i%1e10, 0).UTC(),
+			Password:        yamagiconf.NewSecret(p),
+			Tagged:          k,
+			MarshalEmbedded: MarshalEmbedded{Region: s},
+		}
+		m, err := yamagiconf.Marshal(&c)
+		if err != nil {
+			require.Contains(t, err.Error(), "string is not valid UTF-8")
+			t.Skip("invalid UTF-8")
+		}
+		var loaded MarshalConfig
+		require.NoError(t, yamagiconf.Load(m, &loaded), string(m))
+		require.Equal(t, c, loaded, string(m))
+	})
+}
@@ -0,0 +1,108 @@
+package yamagiconf
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// NamingConvention is a naming convention for YAML keys.
+type NamingConvention int8
+
+const (
+	_ NamingConvention = iota
+
+	// NamingSnakeCase is lower case words separated by `_` like `max_conns`.
+	NamingSnakeCase
+
+	// NamingKebabCase is lower case words separated by `-` like `max-conns`.
+	NamingKebabCase
+
+	// NamingCamelCase is words starting with an upper case letter except
+	// for the first word that is lower case like `maxConns`.
+	NamingCamelCase
+)
+
+var regexNamingConvention = map[NamingConvention]*regexp.Regexp{
+	NamingSnakeCase: regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`),
+	NamingKebabCase: regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`),
+	NamingCamelCase: regexp.MustCompile(`^[a-z][a-z0-9]*([A-Z][a-z0-9]*)*$`),
+}
+
+// String returns the name of the convention like `snake_case`.
+func (c NamingConvention) String() string {
+	switch c {
+	case NamingSnakeCase:
+		return "snake_case"
+	case NamingKebabCase:
+		return "kebab-case"
+	case NamingCamelCase:
+		return "camelCase"
+	}
+	return ""
+}
+
+// matches returns true if name follows convention c.
+// Any name matches the zero value.
+func (c NamingConvention) matches(name string) bool {
+	r, ok := regexNamingConvention[c]
+	return !ok || r.MatchString(name)
+}
+
+// convert returns name converted to convention c.
+// Words of name are separated by `_`, `-`, spaces and changes of case,
+// an upper case sequence like `HTTP` in `maxHTTPConns` is a single word.
+// Returns name unchanged for the zero value.
+func (c NamingConvention) convert(name string) string {
+	words := splitWords(name)
+	switch c {
+	case NamingSnakeCase:
+		return strings.ToLower(strings.Join(words, "_"))
+	case NamingKebabCase:
+		return strings.ToLower(strings.Join(words, "-"))
+	case NamingCamelCase:
+		var b strings.Builder
+		for i, w := range words {
+			w = strings.ToLower(w)
+			if i > 0 {
+				r := []rune(w)
+				r[0] = unicode.ToUpper(r[0])
+				w = string(r)
+			}
+			b.WriteString(w)
+		}
+		return b.String()
+	}
+	return name
+}
+
+// splitWords splits name into words at `_`, `-`, spaces and changes of case.
+func splitWords(name string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+	r := []rune(name)
+	for i, c := range r {
+		switch {
+		case c == '_' || c == '-' || unicode.IsSpace(c):
+			flush()
+			continue
+		case unicode.IsUpper(c) && len(word) > 0:
+			prev := word[len(word)-1]
+			nextLower := i+1 < len(r) && unicode.IsLower(r[i+1])
+			if !unicode.IsUpper(prev) || nextLower {
+				// Start of a word like `Conns` in `maxConns`
+				// or `Server` in `HTTPServer`.
+				flush()
+			}
+		}
+		word = append(word, c)
+	}
+	flush()
+	return words
+}
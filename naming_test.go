@@ -0,0 +1,101 @@
+package yamagiconf_test
+
+import (
+	"testing"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+type NamingEmbedded struct {
+	IdleTimeout string `yaml:"idle_timeout"`
+}
+
+type NamingConfig struct {
+	NamingEmbedded `yaml:",inline"`
+	MaxConns       int32             `yaml:"max_conns"`
+	HTTPServer     string            `yaml:"http_server"`
+	Labels         map[string]string `yaml:"labels"`
+	Weights        map[int32]string  `yaml:"weights"`
+}
+
+func TestYAMLTagConvention(t *testing.T) {
+	require.NoError(t, yamagiconf.ValidateType[NamingConfig](
+		yamagiconf.WithYAMLTagConvention(yamagiconf.NamingSnakeCase),
+	))
+	require.NoError(t, yamagiconf.ValidateType[NamingConfig]())
+
+	for _, td := range []struct {
+		convention yamagiconf.NamingConvention
+		expect     string
+	}{
+		{yamagiconf.NamingKebabCase, `at NamingConfig.NamingEmbedded.IdleTimeout: ` +
+			`yaml struct tag doesn't follow the naming convention: ` +
+			`"idle_timeout" isn't kebab-case, use "idle-timeout"`},
+		{yamagiconf.NamingCamelCase, `at NamingConfig.NamingEmbedded.IdleTimeout: ` +
+			`yaml struct tag doesn't follow the naming convention: ` +
+			`"idle_timeout" isn't camelCase, use "idleTimeout"`},
+	} {
+		t.Run(td.convention.String(), func(t *testing.T) {
+			err := yamagiconf.ValidateType[NamingConfig](
+				yamagiconf.WithYAMLTagConvention(td.convention),
+			)
+			require.ErrorIs(t, err, yamagiconf.ErrTypeYAMLTagConvention)
+			require.Equal(t, td.expect, err.Error())
+		})
+	}
+
+	t.Run("suggestion", func(t *testing.T) {
+		type Config struct {
+			MaxHTTPConns int32 `yaml:"maxHTTPConns"`
+		}
+		err := yamagiconf.ValidateType[Config](
+			yamagiconf.WithYAMLTagConvention(yamagiconf.NamingSnakeCase),
+		)
+		require.ErrorIs(t, err, yamagiconf.ErrTypeYAMLTagConvention)
+		require.Equal(t, `at Config.MaxHTTPConns: yaml struct tag doesn't `+
+			`follow the naming convention: "maxHTTPConns" isn't snake_case, `+
+			`use "max_http_conns"`, err.Error())
+
+		require.NoError(t, yamagiconf.ValidateType[Config](
+			yamagiconf.WithYAMLTagConvention(yamagiconf.NamingCamelCase),
+		))
+	})
+
+	t.Run("loader", func(t *testing.T) {
+		_, err := yamagiconf.NewLoader[NamingConfig](
+			yamagiconf.WithYAMLTagConvention(yamagiconf.NamingKebabCase),
+		)
+		require.ErrorIs(t, err, yamagiconf.ErrTypeYAMLTagConvention)
+	})
+}
+
+func TestMapKeyConvention(t *testing.T) {
+	load := func(src string, opts ...yamagiconf.Option) error {
+		var c NamingConfig
+		return yamagiconf.Load(src, &c, opts...)
+	}
+	const src = `idle_timeout: 1s
+max_conns: 10
+http_server: localhost
+labels:
+  team_name: core
+  teamOwner: jane
+weights:
+  1: a
+`
+	require.NoError(t, load(src))
+
+	err := load(src, yamagiconf.WithMapKeyConvention(yamagiconf.NamingSnakeCase))
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLKeyConvention)
+	require.Equal(t, `at 6:3: NamingConfig.Labels["teamOwner"]: map key doesn't `+
+		`follow the naming convention: "teamOwner" isn't snake_case, `+
+		`use "team_owner"`, err.Error())
+
+	err = load(src, yamagiconf.WithMapKeyConvention(yamagiconf.NamingCamelCase))
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLKeyConvention)
+	require.Equal(t, `at 5:3: NamingConfig.Labels["team_name"]: map key doesn't `+
+		`follow the naming convention: "team_name" isn't camelCase, `+
+		`use "teamName"`, err.Error())
+}
@@ -0,0 +1,129 @@
+package yamagiconf
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Normalizer is implemented by types that need to normalize their values
+// (like lowercasing hostnames, trimming trailing slashes or computing derived
+// fields) before they're validated. Normalize methods are invoked recursively
+// after env vars were applied and before go-playground/validator struct tags
+// and Validate methods are checked. The values of a type are normalized before
+// the value containing them, map keys aren't normalized.
+// Normalize should be implemented with a pointer receiver, otherwise
+// changes to the value are lost.
+type Normalizer interface{ Normalize() error }
+
+// invokeNormalizeRecursively runs the Normalize method for every value
+// in v that implements Normalizer, the contents of v are normalized first.
+// Assumes type of v was validated first using ValidateType.
+// If node != nil then assumes validateYAMLValues was ran first on it.
+func invokeNormalizeRecursively(path string, v reflect.Value, node *yaml.Node) error {
+	tp := v.Type()
+	if !typeHasNormalizers(tp) {
+		return nil
+	}
+	if err := normalizeContents(path, v, node); err != nil {
+		return err
+	}
+	if i := asIface[Normalizer](v, false); i != nil {
+		if err := i.Normalize(); err != nil {
+			return errAtNode(ErrNormalization, tp, path, node, err)
+		}
+	}
+	return nil
+}
+
+// normalizeContents invokes invokeNormalizeRecursively on every field,
+// item and map value of v.
+func normalizeContents(path string, v reflect.Value, node *yaml.Node) error {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if node != nil {
+		node = resolveAlias(node)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, f := range getStructInfo(v.Type()).Fields {
+			var nodeValue *yaml.Node
+			if node != nil && f.YAMLTag != "-" {
+				nodeValue = node
+				if !f.Anonymous {
+					nodeValue = findContentNodeByTag(node, f.YAMLTag)
+				}
+			}
+			path := path + "." + f.Name
+			err := invokeNormalizeRecursively(path, v.FieldByIndex(f.Index), nodeValue)
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if node != nil && node.Kind != yaml.SequenceNode {
+			node = nil
+		}
+		for i := range v.Len() {
+			var nodeItem *yaml.Node
+			if node != nil && i < len(node.Content) {
+				nodeItem = node.Content[i]
+			}
+			path := fmt.Sprintf("%s[%d]", path, i)
+			err := invokeNormalizeRecursively(path, v.Index(i), nodeItem)
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, k := range mapKeysSorted(v) {
+			var nodeValue *yaml.Node
+			if node != nil {
+				nodeValue = findMapValueNode(node, fmt.Sprint(k.Interface()))
+			}
+			// Map values aren't addressable, normalize a copy and write it back.
+			item := reflect.New(v.Type().Elem()).Elem()
+			item.Set(v.MapIndex(k))
+			path := fmt.Sprintf("%s[%v]", path, k)
+			if err := invokeNormalizeRecursively(path, item, nodeValue); err != nil {
+				return err
+			}
+			v.SetMapIndex(k, item)
+		}
+	}
+	return nil
+}
+
+var typeHasNormalizersCache sync.Map // reflect.Type -> bool
+
+// typeHasNormalizers returns true if tp or any type within it
+// implements Normalizer.
+// Assumes that tp has already been validated using ValidateType.
+func typeHasNormalizers(tp reflect.Type) bool {
+	if v, ok := typeHasNormalizersCache.Load(tp); ok {
+		return v.(bool)
+	}
+	has := implementsInterface[Normalizer](tp)
+	if !has {
+		switch tp.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			has = typeHasNormalizers(tp.Elem())
+		case reflect.Struct:
+			for _, f := range getStructInfo(tp).Fields {
+				if typeHasNormalizers(f.Type) {
+					has = true
+					break
+				}
+			}
+		}
+	}
+	typeHasNormalizersCache.Store(tp, has)
+	return has
+}
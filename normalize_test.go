@@ -0,0 +1,135 @@
+package yamagiconf_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+type NormConfig struct {
+	Host     NormHost            `yaml:"host" env:"NORM_TEST_HOST" validate:"hostname"`
+	BaseURL  string              `yaml:"base-url"`
+	Upstream *NormUpstream       `yaml:"upstream"`
+	Backends []NormUpstream      `yaml:"backends"`
+	Routes   map[string]NormHost `yaml:"routes"`
+
+	// Derived is computed by Normalize.
+	Derived string `yaml:"-"`
+}
+
+func (c *NormConfig) Normalize() error {
+	c.BaseURL = strings.TrimRight(c.BaseURL, "/")
+	// Fields are normalized before the struct containing them.
+	c.Derived = string(c.Host) + c.BaseURL
+	return nil
+}
+
+type NormHost string
+
+var ErrNormTestEmptyHost = errors.New("empty host")
+
+func (h *NormHost) Normalize() error {
+	if *h == "" {
+		return ErrNormTestEmptyHost
+	}
+	*h = NormHost(strings.ToLower(string(*h)))
+	return nil
+}
+
+type NormUpstream struct {
+	Host NormHost `yaml:"host"`
+	Port uint16   `yaml:"port"`
+}
+
+func (u *NormUpstream) Normalize() error {
+	if u.Port == 0 {
+		return yamagiconf.FieldErr("Port", errors.New("must not be zero"))
+	}
+	return nil
+}
+
+func TestNormalizer(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		c, err := LoadSrc[NormConfig](`host: Example.COM
+base-url: /api/
+upstream:
+  host: UP.local
+  port: 80
+backends:
+  - host: A.local
+    port: 81
+routes:
+  foo: FOO.local
+`)
+		require.NoError(t, err)
+		require.Equal(t, NormConfig{
+			Host:     "example.com",
+			BaseURL:  "/api",
+			Upstream: &NormUpstream{Host: "up.local", Port: 80},
+			Backends: []NormUpstream{{Host: "a.local", Port: 81}},
+			Routes:   map[string]NormHost{"foo": "foo.local"},
+			Derived:  "example.com/api",
+		}, *c)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		// Env vars are applied before normalization.
+		t.Setenv("NORM_TEST_HOST", "FROM.ENV")
+		c, err := LoadSrc[NormConfig](`host: example.com
+base-url: ''
+upstream: null
+backends: null
+routes: null
+`)
+		require.NoError(t, err)
+		require.Equal(t, NormHost("from.env"), c.Host)
+	})
+
+	t.Run("before_validation", func(t *testing.T) {
+		// Validation rules are checked against the normalized value.
+		_, err := LoadSrc[NormConfig](`host: Example_COM
+base-url: ''
+upstream: null
+backends: null
+routes: null
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrValidationTag)
+		require.Equal(t, `at 1:7: "host" violates validation rule: "hostname", `+
+			`got example_com`, err.Error())
+	})
+
+	t.Run("err", func(t *testing.T) {
+		_, err := LoadSrc[NormConfig](`host: example.com
+base-url: ''
+upstream: null
+backends: null
+routes:
+  foo: bar.local
+  bar: ''
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrNormalization)
+		require.ErrorIs(t, err, ErrNormTestEmptyHost)
+		require.Equal(t, `at 7:8: at NormConfig.Routes[bar]: `+
+			`normalization: empty host`, err.Error())
+	})
+
+	t.Run("err_field", func(t *testing.T) {
+		_, err := LoadSrc[NormConfig](`host: example.com
+base-url: ''
+upstream: null
+backends:
+  - host: a.local
+    port: 81
+  - host: b.local
+    port: 0
+routes: null
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrNormalization)
+		require.Equal(t, `at 8:11: at NormConfig.Backends[1].Port: `+
+			`normalization: must not be zero`, err.Error())
+	})
+}
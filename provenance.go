@@ -0,0 +1,253 @@
+package yamagiconf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Provenance maps the YAML paths of all values of a loaded configuration
+// to their sources. Paths consist of yaml tags separated by dots with sequence
+// indexes and map keys in square brackets, like `server.port`,
+// `servers[1].host` or `labels[env]`. Keys of embedded inline maps are
+// separated by dots like fields. Fields ignored by YAML (`yaml:"-"`) are
+// included only if they were set by an env var under their Go field name
+// prefixed with `-`, like `-Secret` or `server.-Token`, to keep them apart
+// from the paths of YAML keys. The fields of their struct values aren't
+// included.
+type Provenance map[string]Source
+
+// Source describes where a value of a loaded configuration came from.
+type Source struct {
+	// File is the path of the YAML file or "" if the document was loaded
+	// from memory.
+	File string
+
+	// Line and Column are the location of the value in the YAML document,
+	// both are 0 if the value didn't come from the YAML document.
+	Line, Column int
+
+	// EnvVar is the name of the env var that overwrote the value, if any.
+	EnvVar string
+
+	// Default is true if the value was filled in from a `default` struct tag.
+	Default bool
+
+	// Overrides is the source of the value overwritten by EnvVar, if any.
+	Overrides *Source
+}
+
+// String returns the source formatted like `config.yaml:12:5`, `env DB_PORT`
+// or `default`.
+func (s Source) String() string {
+	switch {
+	case s.EnvVar != "":
+		return "env " + s.EnvVar
+	case s.Default:
+		return "default"
+	case s.File != "":
+		return fmt.Sprintf("%s:%d:%d", s.File, s.Line, s.Column)
+	}
+	return fmt.Sprintf("%d:%d", s.Line, s.Column)
+}
+
+// Explain returns a human-readable description of the source of the value
+// at yamlPath like `server.port: env DB_PORT (overrides config.yaml:12:5)`.
+func (p Provenance) Explain(yamlPath string) string {
+	s, ok := p[yamlPath]
+	if !ok {
+		return yamlPath + ": unknown path"
+	}
+	if s.Overrides != nil {
+		return fmt.Sprintf("%s: %s (overrides %s)", yamlPath, s, s.Overrides)
+	}
+	return yamlPath + ": " + s.String()
+}
+
+// LoadFileWithProvenance behaves like LoadFile and additionally returns
+// the source of every value of the loaded configuration.
+func LoadFileWithProvenance[T any](
+	yamlFilePath string, config *T, opts ...Option,
+) (Provenance, error) {
+	if config == nil {
+		return nil, ErrConfigNil
+	}
+	o := newOptions(opts)
+	if err := validateType[T](o); err != nil {
+		return nil, err
+	}
+	return loadFileWithProvenance(o, yamlFilePath, config)
+}
+
+// LoadWithProvenance behaves like Load and additionally returns
+// the source of every value of the loaded configuration.
+func LoadWithProvenance[T any, S string | []byte](
+	yamlSource S, config *T, opts ...Option,
+) (Provenance, error) {
+	if config == nil {
+		return nil, ErrConfigNil
+	}
+	o := newOptions(opts)
+	if err := validateType[T](o); err != nil {
+		return nil, err
+	}
+	prov := Provenance{}
+	if err := load(context.Background(), o, yamlSource, config, "", prov); err != nil {
+		return nil, err
+	}
+	return prov, nil
+}
+
+// loadFileWithProvenance reads and loads the file at yamlFilePath.
+// Assumes that T has already been validated using ValidateType.
+func loadFileWithProvenance[T any](
+	o *options, yamlFilePath string, config *T,
+) (Provenance, error) {
+	if config == nil {
+		return nil, ErrConfigNil
+	}
+	yamlSrcBytes, err := os.ReadFile(yamlFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %q: %w", yamlFilePath, err)
+	}
+	prov := Provenance{}
+	err = load(context.Background(), o, yamlSrcBytes, config, yamlFilePath, prov)
+	if err != nil {
+		return nil, err
+	}
+	return prov, nil
+}
+
+// record records the sources of node of type tp at path and all its contents.
+// Nodes in defaults and all their contents were filled in from `default`
+// struct tags.
+// Assumes that tp has already been validated using ValidateType and
+// that node has been validated using validateYAMLValues.
+func (p Provenance) record(
+	file, path string, tp reflect.Type, node *yaml.Node,
+	defaults map[*yaml.Node]struct{},
+) {
+	if _, ok := defaults[node]; ok {
+		p[path] = Source{Default: true}
+	} else {
+		p[path] = Source{File: file, Line: node.Line, Column: node.Column}
+	}
+	for tp.Kind() == reflect.Pointer {
+		tp = tp.Elem()
+	}
+	node = resolveAlias(node)
+	if node.Kind == yaml.ScalarNode {
+		return
+	}
+	if _, ok := defaults[node]; ok {
+		// The contents of defaults are defaults too.
+		for _, n := range node.Content {
+			defaults[n] = struct{}{}
+		}
+	}
+
+	switch tp.Kind() {
+	case reflect.Struct:
+		if isPlainStruct(tp) {
+			p.recordFields(file, path, tp, node, defaults)
+		}
+	case reflect.Slice, reflect.Array:
+		for i, n := range node.Content {
+			p.record(file, fmt.Sprintf("%s[%d]", path, i), tp.Elem(), n, defaults)
+		}
+	case reflect.Map:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			path := fmt.Sprintf("%s[%s]", path, node.Content[i].Value)
+			p.record(file, path, tp.Elem(), node.Content[i+1], defaults)
+		}
+	}
+}
+
+// recordFields records the sources of all fields of struct type tp
+// including the fields of embedded inline structs and the keys of
+// embedded inline maps.
+func (p Provenance) recordFields(
+	file, path string, tp reflect.Type, node *yaml.Node,
+	defaults map[*yaml.Node]struct{},
+) {
+	inlineMap := p.recordStructFields(file, path, tp, node, defaults)
+	if inlineMap == nil {
+		return
+	}
+	keys := getStructInfo(tp).Keys
+	recorded := map[string]struct{}{}
+	mappings := append([]*yaml.Node{node}, mergedMappings(node)...)
+	for _, m := range mappings {
+		// Keys of the mapping itself override merged keys.
+		for i := 0; i+1 < len(m.Content); i += 2 {
+			k := m.Content[i]
+			if k.Tag == "!!merge" {
+				continue
+			}
+			if _, ok := keys[k.Value]; ok {
+				continue
+			}
+			if _, ok := recorded[k.Value]; ok {
+				continue
+			}
+			recorded[k.Value] = struct{}{}
+			p.record(file, joinYAMLPath(path, k.Value),
+				inlineMap.Elem(), m.Content[i+1], defaults)
+		}
+	}
+}
+
+// recordStructFields records the sources of all fields of struct type tp
+// including the fields of embedded inline structs and returns the type of
+// the embedded inline map, if any.
+func (p Provenance) recordStructFields(
+	file, path string, tp reflect.Type, node *yaml.Node,
+	defaults map[*yaml.Node]struct{},
+) (inlineMap reflect.Type) {
+	for _, f := range getStructInfo(tp).Fields {
+		switch {
+		case f.YAMLTag == "-":
+			if envVar, _ := fieldEnvVar(f.StructField); envVar != "" {
+				path := joinYAMLPath(path, "-"+f.Name)
+				p[path] = Source{EnvVar: envVar}
+			}
+		case f.Anonymous:
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Map {
+				inlineMap = embedded
+			} else if isPlainStruct(embedded) {
+				m := p.recordStructFields(file, path, embedded, node, defaults)
+				if m != nil {
+					inlineMap = m
+				}
+			}
+		default:
+			n := findContentNodeByTag(node, f.YAMLTag)
+			if n == nil {
+				continue
+			}
+			path := joinYAMLPath(path, f.YAMLTag)
+			p.record(file, path, f.Type, n, defaults)
+			if envVar, _ := fieldEnvVar(f.StructField); envVar != "" {
+				overridden := p[path]
+				p[path] = Source{EnvVar: envVar, Overrides: &overridden}
+			}
+		}
+	}
+	return inlineMap
+}
+
+// joinYAMLPath returns the path of field or inline map key name in
+// the mapping at path.
+func joinYAMLPath(path, name string) string {
+	if path == "" {
+		return name // Field of the root struct.
+	}
+	return path + "." + name
+}
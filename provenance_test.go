@@ -0,0 +1,177 @@
+package yamagiconf_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+type ProvConfig struct {
+	Server   ProvServer            `yaml:"server"`
+	Backends []ProvServer          `yaml:"backends"`
+	Labels   map[string]string     `yaml:"labels"`
+	Limits   map[string]*ProvLimit `yaml:"limits"`
+	Secret   string                `yaml:"-" env:"PROV_TEST_SECRET"`
+}
+
+type ProvServer struct {
+	Host    string        `yaml:"host"`
+	Port    uint16        `yaml:"port" env:"PROV_TEST_PORT"`
+	Timeout time.Duration `yaml:"timeout" default:"30s"`
+}
+
+type ProvLimit struct {
+	Max  uint32    `yaml:"max"`
+	Opts *ProvOpts `yaml:"opts" default:"{burst: 5}"`
+}
+
+type ProvOpts struct {
+	Burst uint32 `yaml:"burst"`
+}
+
+const provTestSrc = `server:
+  host: localhost
+  port: 8080
+backends:
+  - host: a.local
+    port: 81
+    timeout: 5s
+labels:
+  env: prod
+limits:
+  api:
+    max: 10
+`
+
+func TestLoadWithProvenance(t *testing.T) {
+	var c ProvConfig
+	p, err := yamagiconf.LoadWithProvenance(provTestSrc, &c)
+	require.NoError(t, err)
+	require.Equal(t, yamagiconf.Provenance{
+		"server":                 {Line: 2, Column: 3},
+		"server.host":            {Line: 2, Column: 9},
+		"server.port":            {Line: 3, Column: 9},
+		"server.timeout":         {Default: true},
+		"backends":               {Line: 5, Column: 3},
+		"backends[0]":            {Line: 5, Column: 5},
+		"backends[0].host":       {Line: 5, Column: 11},
+		"backends[0].port":       {Line: 6, Column: 11},
+		"backends[0].timeout":    {Line: 7, Column: 14},
+		"labels":                 {Line: 9, Column: 3},
+		"labels[env]":            {Line: 9, Column: 8},
+		"limits":                 {Line: 11, Column: 3},
+		"limits[api]":            {Line: 12, Column: 5},
+		"limits[api].max":        {Line: 12, Column: 10},
+		"limits[api].opts":       {Default: true},
+		"limits[api].opts.burst": {Default: true},
+	}, p)
+	require.Equal(t, uint32(5), c.Limits["api"].Opts.Burst)
+
+	require.Equal(t, "server.host: 2:9", p.Explain("server.host"))
+	require.Equal(t, "server.timeout: default", p.Explain("server.timeout"))
+	require.Equal(t, "server.nope: unknown path", p.Explain("server.nope"))
+}
+
+func TestLoadWithProvenanceEnv(t *testing.T) {
+	t.Setenv("PROV_TEST_PORT", "9090")
+	t.Setenv("PROV_TEST_SECRET", "secret")
+
+	var c ProvConfig
+	p, err := yamagiconf.LoadWithProvenance(provTestSrc, &c)
+	require.NoError(t, err)
+	require.Equal(t, uint16(9090), c.Server.Port)
+	require.Equal(t, yamagiconf.Source{
+		EnvVar:    "PROV_TEST_PORT",
+		Overrides: &yamagiconf.Source{Line: 3, Column: 9},
+	}, p["server.port"])
+	require.Equal(t, "server.port: env PROV_TEST_PORT (overrides 3:9)",
+		p.Explain("server.port"))
+	require.Equal(t, "backends[0].port: env PROV_TEST_PORT (overrides 6:11)",
+		p.Explain("backends[0].port"))
+	require.Equal(t, "-Secret: env PROV_TEST_SECRET", p.Explain("-Secret"))
+	_, ok := p["secret"]
+	require.False(t, ok)
+}
+
+func TestLoadWithProvenanceIgnoredEnvSameName(t *testing.T) {
+	type TestConfig struct {
+		Value string `yaml:"token"`
+		Token string `yaml:"-" env:"PROV_TEST_TOKEN"`
+		Copy  string `yaml:"-"`
+	}
+	t.Setenv("PROV_TEST_TOKEN", "t")
+	var c TestConfig
+	p, err := yamagiconf.LoadWithProvenance("token: x\n", &c)
+	require.NoError(t, err)
+	require.Equal(t, yamagiconf.Provenance{
+		"token":  {Line: 1, Column: 8},
+		"-Token": {EnvVar: "PROV_TEST_TOKEN"},
+	}, p)
+}
+
+func TestLoadWithProvenanceIgnoredNoEnv(t *testing.T) {
+	var c ProvConfig
+	p, err := yamagiconf.LoadWithProvenance(provTestSrc, &c)
+	require.NoError(t, err)
+	_, ok := p["-Secret"]
+	require.False(t, ok, "fields ignored by YAML and not set by env "+
+		"must not be included")
+}
+
+func TestLoadWithProvenanceInlineMap(t *testing.T) {
+	type Labels map[string]string
+	type TestConfig struct {
+		Labels `yaml:",inline"`
+		Name   string `yaml:"name"`
+	}
+
+	var c TestConfig
+	p, err := yamagiconf.LoadWithProvenance(`name: root
+foo: bar
+baz: qux
+`, &c)
+	require.NoError(t, err)
+	require.Equal(t, Labels{"foo": "bar", "baz": "qux"}, c.Labels)
+	require.Equal(t, yamagiconf.Provenance{
+		"name": {Line: 1, Column: 7},
+		"foo":  {Line: 2, Column: 6},
+		"baz":  {Line: 3, Column: 6},
+	}, p)
+	require.Equal(t, "foo: 2:6", p.Explain("foo"))
+}
+
+func TestLoadFileWithProvenance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(provTestSrc), 0o664))
+
+	var c ProvConfig
+	p, err := yamagiconf.LoadFileWithProvenance(path, &c)
+	require.NoError(t, err)
+	require.Equal(t, "server.port: "+path+":3:9", p.Explain("server.port"))
+
+	l, err := yamagiconf.NewLoader[ProvConfig]()
+	require.NoError(t, err)
+	p, err = l.LoadFileWithProvenance(path, &c)
+	require.NoError(t, err)
+	require.Equal(t, "labels[env]: "+path+":9:8", p.Explain("labels[env]"))
+
+	p, err = l.LoadWithProvenance([]byte(provTestSrc), &c)
+	require.NoError(t, err)
+	require.Equal(t, "labels[env]: 9:8", p.Explain("labels[env]"))
+}
+
+func TestLoadWithProvenanceErr(t *testing.T) {
+	var c ProvConfig
+	p, err := yamagiconf.LoadWithProvenance("server: null\n", &c)
+	require.Error(t, err)
+	require.Nil(t, p)
+
+	p, err = yamagiconf.LoadWithProvenance[ProvConfig](provTestSrc, nil)
+	require.ErrorIs(t, err, yamagiconf.ErrConfigNil)
+	require.Nil(t, p)
+}
@@ -0,0 +1,301 @@
+package yamagiconf
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// validateRefTag returns an error if the `ref` struct tag of f is invalid.
+// A ref tag is a dot-separated path of yaml tags starting at the root type
+// that must lead to a map with string keys. It's allowed on fields of
+// string types, pointers to string types, slices and arrays of string types
+// and maps with string keys, which reference by key.
+func validateRefTag(root reflect.Type, f reflect.StructField) error {
+	ref, ok := f.Tag.Lookup("ref")
+	if !ok {
+		return nil
+	}
+	if !isRefType(f.Type) {
+		return fmt.Errorf("%w: unsupported type %s", ErrTypeInvalidRefTag, f.Type)
+	}
+	tp, err := resolveRefType(root, ref)
+	if err != nil {
+		return fmt.Errorf("%w: %q: %w", ErrTypeInvalidRefTag, ref, err)
+	}
+	if tp.Kind() != reflect.Map || tp.Key().Kind() != reflect.String {
+		return fmt.Errorf("%w: %q: must refer to a map with string keys, "+
+			"but refers to %s", ErrTypeInvalidRefTag, ref, tp)
+	}
+	return nil
+}
+
+func isRefType(tp reflect.Type) bool {
+	switch tp.Kind() {
+	case reflect.String:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return tp.Elem().Kind() == reflect.String
+	case reflect.Map:
+		return tp.Key().Kind() == reflect.String
+	}
+	return false
+}
+
+// resolveRefType returns the type of the field at ref relative to root.
+func resolveRefType(root reflect.Type, ref string) (reflect.Type, error) {
+	tp := root
+	for _, yamlTag := range strings.Split(ref, ".") {
+		for tp.Kind() == reflect.Pointer {
+			tp = tp.Elem()
+		}
+		if !isPlainStruct(tp) {
+			return nil, fmt.Errorf("%q isn't a field of a struct", yamlTag)
+		}
+		index := findFieldIndexByYAMLTag(tp, yamlTag)
+		if index == nil {
+			return nil, fmt.Errorf("field %q not found in %s", yamlTag, tp)
+		}
+		tp = tp.FieldByIndex(index).Type
+	}
+	return tp, nil
+}
+
+// findFieldIndexByYAMLTag returns the index of the field with yamlTag
+// in struct type tp including fields of embedded inline structs.
+// Returns nil if no such field exists.
+func findFieldIndexByYAMLTag(tp reflect.Type, yamlTag string) []int {
+	for _, f := range getStructInfo(tp).Fields {
+		switch {
+		case f.Anonymous && f.YAMLTag != "-":
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if !isPlainStruct(embedded) {
+				continue
+			}
+			if i := findFieldIndexByYAMLTag(embedded, yamlTag); i != nil {
+				return append(append([]int{}, f.Index...), i...)
+			}
+		case f.YAMLTag == yamlTag:
+			return f.Index
+		}
+	}
+	return nil
+}
+
+var typeHasRefsCache sync.Map // reflect.Type -> bool
+
+// typeHasRefs returns true if tp contains any field with a `ref` struct tag.
+// Assumes that tp has already been validated using ValidateType.
+func typeHasRefs(tp reflect.Type) bool {
+	if v, ok := typeHasRefsCache.Load(tp); ok {
+		return v.(bool)
+	}
+	has := false
+	switch tp.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		has = typeHasRefs(tp.Elem())
+	case reflect.Map:
+		has = typeHasRefs(tp.Elem())
+	case reflect.Struct:
+		if !isPlainStruct(tp) {
+			break
+		}
+		for _, f := range getStructInfo(tp).Fields {
+			if _, ok := f.Tag.Lookup("ref"); ok || typeHasRefs(f.Type) {
+				has = true
+				break
+			}
+		}
+	}
+	typeHasRefsCache.Store(tp, has)
+	return has
+}
+
+// refChecker checks the values of fields with a `ref` struct tag
+// against the keys of the referenced maps.
+type refChecker struct {
+	root  reflect.Value
+	names map[string]map[string]struct{} // ref -> keys of referenced map
+}
+
+// validateRefs returns an error if any value of a field with a `ref` struct tag
+// in root refers to a name that isn't a key of the referenced map.
+// Errors are reported by line:column if rootNode != nil.
+// Assumes that the type of root has already been validated using ValidateType.
+func validateRefs(path string, root reflect.Value, rootNode *yaml.Node) error {
+	if !typeHasRefs(root.Type()) {
+		return nil
+	}
+	c := &refChecker{root: root, names: map[string]map[string]struct{}{}}
+	return c.traverse(path, root, rootNode)
+}
+
+func (c *refChecker) traverse(path string, v reflect.Value, node *yaml.Node) error {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !typeHasRefs(v.Type()) {
+		return nil
+	}
+	if node != nil {
+		node = resolveAlias(node)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, f := range getStructInfo(v.Type()).Fields {
+			fv := v.FieldByIndex(f.Index)
+			var nodeValue *yaml.Node
+			if node != nil && f.YAMLTag != "-" {
+				nodeValue = node
+				if !f.Anonymous {
+					nodeValue = findContentNodeByTag(node, f.YAMLTag)
+				}
+			}
+			path := path + "." + f.Name
+			if ref, ok := f.Tag.Lookup("ref"); ok {
+				err := c.checkField(ref, f.YAMLTag, path, fv, nodeValue)
+				if err != nil {
+					return err
+				}
+			}
+			if err := c.traverse(path, fv, nodeValue); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if node != nil && node.Kind != yaml.SequenceNode {
+			node = nil
+		}
+		for i := range v.Len() {
+			var nodeItem *yaml.Node
+			if node != nil && i < len(node.Content) {
+				nodeItem = node.Content[i]
+			}
+			path := fmt.Sprintf("%s[%d]", path, i)
+			if err := c.traverse(path, v.Index(i), nodeItem); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, k := range mapKeysSorted(v) {
+			var nodeValue *yaml.Node
+			if node != nil {
+				nodeValue = findMapValueNode(node, fmt.Sprint(k.Interface()))
+			}
+			path := fmt.Sprintf("%s[%v]", path, k)
+			if err := c.traverse(path, v.MapIndex(k), nodeValue); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkField checks the value(s) of field value v with ref tag ref.
+func (c *refChecker) checkField(
+	ref, yamlTag, path string, v reflect.Value, node *yaml.Node,
+) error {
+	if node != nil {
+		node = resolveAlias(node)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return c.check(ref, yamlTag, path, v.String(), node)
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return c.check(ref, yamlTag, path, v.Elem().String(), node)
+	case reflect.Slice, reflect.Array:
+		if node != nil && node.Kind != yaml.SequenceNode {
+			node = nil
+		}
+		for i := range v.Len() {
+			var nodeItem *yaml.Node
+			if node != nil && i < len(node.Content) {
+				nodeItem = node.Content[i]
+			}
+			path := fmt.Sprintf("%s[%d]", path, i)
+			err := c.check(ref, yamlTag, path, v.Index(i).String(), nodeItem)
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, k := range mapKeysSorted(v) {
+			var nodeKey *yaml.Node
+			if node != nil && node.Kind == yaml.MappingNode {
+				for i := 0; i < len(node.Content); i += 2 {
+					if node.Content[i].Value == k.String() {
+						nodeKey = node.Content[i]
+						break
+					}
+				}
+			}
+			path := fmt.Sprintf("%s[%s]", path, k.String())
+			if err := c.check(ref, yamlTag, path, k.String(), nodeKey); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *refChecker) check(ref, yamlTag, path, name string, node *yaml.Node) error {
+	names := c.namesOf(ref)
+	if _, ok := names[name]; ok {
+		return nil
+	}
+	valid := make([]string, 0, len(names))
+	for n := range names {
+		valid = append(valid, strconv.Quote(n))
+	}
+	sort.Strings(valid)
+	if node == nil {
+		return fmt.Errorf("at %s: %w %q in %q (valid: %s)",
+			path, ErrRefUndefined, name, ref, strings.Join(valid, ", "))
+	}
+	return fmt.Errorf("at %d:%d: %q (%s): %w %q in %q (valid: %s)",
+		node.Line, node.Column, yamlTag, path,
+		ErrRefUndefined, name, ref, strings.Join(valid, ", "))
+}
+
+// namesOf returns the keys of the map referenced by ref.
+func (c *refChecker) namesOf(ref string) map[string]struct{} {
+	if names, ok := c.names[ref]; ok {
+		return names
+	}
+	names := map[string]struct{}{}
+	c.names[ref] = names
+
+	v := c.root
+	for _, yamlTag := range strings.Split(ref, ".") {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return names
+			}
+			v = v.Elem()
+		}
+		f, err := v.FieldByIndexErr(findFieldIndexByYAMLTag(v.Type(), yamlTag))
+		if err != nil {
+			return names // Nil embedded struct pointer.
+		}
+		v = f
+	}
+	for _, k := range v.MapKeys() {
+		names[k.String()] = struct{}{}
+	}
+	return names
+}
@@ -0,0 +1,257 @@
+package yamagiconf_test
+
+import (
+	"testing"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+type RefTestConfig struct {
+	Clusters map[string]RefTestCluster `yaml:"clusters"`
+	Default  string                    `yaml:"default" ref:"clusters"`
+	Fallback *string                   `yaml:"fallback" ref:"clusters"`
+	Services []RefTestService          `yaml:"services"`
+	Weights  map[string]int32          `yaml:"weights" ref:"clusters"`
+}
+
+type RefTestCluster struct {
+	Host string `yaml:"host"`
+}
+
+type RefTestService struct {
+	Name     string   `yaml:"name"`
+	Clusters []string `yaml:"clusters" ref:"clusters"`
+}
+
+func TestRef(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		c, err := LoadSrc[RefTestConfig](`clusters:
+  eu:
+    host: eu.local
+  us:
+    host: us.local
+default: eu
+fallback: us
+services:
+  - name: api
+    clusters:
+      - eu
+      - us
+weights:
+  eu: 2
+  us: 1
+`)
+		require.NoError(t, err)
+		require.Equal(t, "eu", c.Default)
+		require.Equal(t, "us", *c.Fallback)
+		require.Equal(t, []string{"eu", "us"}, c.Services[0].Clusters)
+		require.NoError(t, yamagiconf.Validate(*c))
+	})
+
+	t.Run("ok_null", func(t *testing.T) {
+		_, err := LoadSrc[RefTestConfig](`clusters:
+  eu:
+    host: eu.local
+default: eu
+fallback: null
+services: null
+weights: null
+`)
+		require.NoError(t, err)
+	})
+
+	t.Run("err_string", func(t *testing.T) {
+		_, err := LoadSrc[RefTestConfig](`clusters:
+  eu:
+    host: eu.local
+  us:
+    host: us.local
+default: asia
+fallback: null
+services: null
+weights: null
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrRefUndefined)
+		require.Equal(t, `at 6:10: "default" (RefTestConfig.Default): `+
+			`reference to undefined name "asia" in "clusters" `+
+			`(valid: "eu", "us")`, err.Error())
+	})
+
+	t.Run("err_pointer", func(t *testing.T) {
+		_, err := LoadSrc[RefTestConfig](`clusters:
+  eu:
+    host: eu.local
+default: eu
+fallback: us
+services: null
+weights: null
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrRefUndefined)
+		require.Equal(t, `at 5:11: "fallback" (RefTestConfig.Fallback): `+
+			`reference to undefined name "us" in "clusters" `+
+			`(valid: "eu")`, err.Error())
+	})
+
+	t.Run("err_slice_item", func(t *testing.T) {
+		_, err := LoadSrc[RefTestConfig](`clusters:
+  eu:
+    host: eu.local
+default: eu
+fallback: null
+services:
+  - name: api
+    clusters:
+      - eu
+      - us
+weights: null
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrRefUndefined)
+		require.Equal(t, `at 10:9: "clusters" `+
+			`(RefTestConfig.Services[0].Clusters[1]): `+
+			`reference to undefined name "us" in "clusters" `+
+			`(valid: "eu")`, err.Error())
+	})
+
+	t.Run("err_map_key", func(t *testing.T) {
+		_, err := LoadSrc[RefTestConfig](`clusters:
+  eu:
+    host: eu.local
+default: eu
+fallback: null
+services: null
+weights:
+  eu: 2
+  us: 1
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrRefUndefined)
+		require.Equal(t, `at 9:3: "weights" (RefTestConfig.Weights[us]): `+
+			`reference to undefined name "us" in "clusters" `+
+			`(valid: "eu")`, err.Error())
+	})
+
+	t.Run("err_empty_map", func(t *testing.T) {
+		_, err := LoadSrc[RefTestConfig](`clusters: null
+default: eu
+fallback: null
+services: null
+weights: null
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrRefUndefined)
+		require.Equal(t, `at 2:10: "default" (RefTestConfig.Default): `+
+			`reference to undefined name "eu" in "clusters" `+
+			`(valid: )`, err.Error())
+	})
+
+	t.Run("err_validate", func(t *testing.T) {
+		err := yamagiconf.Validate(RefTestConfig{
+			Clusters: map[string]RefTestCluster{"eu": {Host: "eu.local"}},
+			Default:  "eu",
+			Services: []RefTestService{{Name: "api", Clusters: []string{"us"}}},
+		})
+		require.ErrorIs(t, err, yamagiconf.ErrRefUndefined)
+		require.Equal(t, `at RefTestConfig.Services[0].Clusters[0]: `+
+			`reference to undefined name "us" in "clusters" `+
+			`(valid: "eu")`, err.Error())
+	})
+}
+
+func TestRefNested(t *testing.T) {
+	type Pool struct {
+		Size int32 `yaml:"size"`
+	}
+	type Resources struct {
+		Pools map[string]Pool `yaml:"pools"`
+	}
+	type Embedded struct {
+		Resources *Resources `yaml:"resources"`
+	}
+	type TestConfig struct {
+		Embedded `yaml:",inline"`
+		Use      string `yaml:"use" ref:"resources.pools"`
+	}
+
+	c, err := LoadSrc[TestConfig](`resources:
+  pools:
+    small:
+      size: 1
+use: small
+`)
+	require.NoError(t, err)
+	require.Equal(t, "small", c.Use)
+
+	_, err = LoadSrc[TestConfig](`resources:
+  pools:
+    small:
+      size: 1
+use: large
+`)
+	require.ErrorIs(t, err, yamagiconf.ErrRefUndefined)
+	require.Equal(t, `at 5:6: "use" (TestConfig.Use): `+
+		`reference to undefined name "large" in "resources.pools" `+
+		`(valid: "small")`, err.Error())
+
+	_, err = LoadSrc[TestConfig]("resources: null\nuse: small\n")
+	require.ErrorIs(t, err, yamagiconf.ErrRefUndefined)
+}
+
+func TestValidateTypeErrInvalidRefTag(t *testing.T) {
+	type Cluster struct {
+		Host string `yaml:"host"`
+	}
+
+	t.Run("path_not_found", func(t *testing.T) {
+		type TestConfig struct {
+			Clusters map[string]Cluster `yaml:"clusters"`
+			Use      string             `yaml:"use" ref:"cluster"`
+		}
+		err := yamagiconf.ValidateType[TestConfig]()
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidRefTag)
+		require.Equal(t, `at TestConfig.Use: invalid ref struct tag: "cluster": `+
+			`field "cluster" not found in yamagiconf_test.TestConfig`, err.Error())
+	})
+
+	t.Run("not_a_map", func(t *testing.T) {
+		type TestConfig struct {
+			Clusters []Cluster `yaml:"clusters"`
+			Use      string    `yaml:"use" ref:"clusters"`
+		}
+		err := yamagiconf.ValidateType[TestConfig]()
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidRefTag)
+		require.Equal(t, `at TestConfig.Use: invalid ref struct tag: "clusters": `+
+			`must refer to a map with string keys, `+
+			`but refers to []yamagiconf_test.Cluster`, err.Error())
+	})
+
+	t.Run("non_string_keys", func(t *testing.T) {
+		type TestConfig struct {
+			Clusters map[int32]Cluster `yaml:"clusters"`
+			Use      string            `yaml:"use" ref:"clusters"`
+		}
+		err := yamagiconf.ValidateType[TestConfig]()
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidRefTag)
+	})
+
+	t.Run("through_non_struct", func(t *testing.T) {
+		type TestConfig struct {
+			Clusters map[string]Cluster `yaml:"clusters"`
+			Use      string             `yaml:"use" ref:"clusters.host"`
+		}
+		err := yamagiconf.ValidateType[TestConfig]()
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidRefTag)
+		require.Equal(t, `at TestConfig.Use: invalid ref struct tag: `+
+			`"clusters.host": "host" isn't a field of a struct`, err.Error())
+	})
+
+	t.Run("unsupported_field_type", func(t *testing.T) {
+		type TestConfig struct {
+			Clusters map[string]Cluster `yaml:"clusters"`
+			Use      int32              `yaml:"use" ref:"clusters"`
+		}
+		err := yamagiconf.ValidateType[TestConfig]()
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidRefTag)
+		require.Equal(t, `at TestConfig.Use: invalid ref struct tag: `+
+			`unsupported type int32`, err.Error())
+	})
+}
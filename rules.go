@@ -0,0 +1,233 @@
+package yamagiconf
+
+import (
+	"encoding"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rules toggles the restrictions of ValidateType and the loader.
+// The zero value enforces the default rules documented on LoadFile
+// and ValidateType.
+//
+// The following restrictions are fixed because the loader can't
+// decode files violating them correctly or they're part of the contract
+// of the configuration type:
+//   - keys must be specified by the Go type and keys without a default
+//     value must be present.
+//   - values of types implementing encoding.TextUnmarshaler must be scalars.
+//   - values of fields tagged `secret:"env-only"` must be null or empty.
+//   - files must contain a single document and aliases of documents loaded
+//     by LoadStream must refer to anchors of the same document.
+//   - all restrictions of ValidateType other than those on int and uint
+//     and the naming convention of yaml struct tags.
+type Rules struct {
+	// AllowIntUint allows fields of type int and uint the width of which
+	// depends on the platform.
+	AllowIntUint bool
+
+	// YAMLTagConvention requires all yaml struct tags to follow
+	// the naming convention. Any tag is allowed if zero.
+	YAMLTagConvention NamingConvention
+
+	// AllowBoolVariants allows the YAML 1.1 boolean literals
+	// `yes`, `no`, `on`, `off`, `y` and `n` and their capitalized variants.
+	AllowBoolVariants bool
+
+	// AllowNullVariants allows the null literals `~`, `Null` and `NULL`.
+	AllowNullVariants bool
+
+	// AllowNullOnNonPointer allows assigning null to non-pointer types,
+	// which leaves the zero value. Null items of sequences become zero value
+	// items except for item types implementing encoding.TextUnmarshaler
+	// or yaml.Unmarshaler, which can't be null.
+	AllowNullOnNonPointer bool
+
+	// AllowYAMLTags allows YAML tags like `!!str`.
+	AllowYAMLTags bool
+
+	// AllowMergeKeys allows YAML merge keys (`<<`). Keys of the merged mappings
+	// are subject to the same rules as the keys of the mapping itself.
+	AllowMergeKeys bool
+
+	// ForbidAnchors forbids YAML anchors and aliases entirely.
+	ForbidAnchors bool
+
+	// AllowUnusedAnchors allows anchors that are never referenced.
+	AllowUnusedAnchors bool
+
+	// AllowAnchorRedefinition allows defining an anchor name more than once.
+	// Aliases refer to the closest preceding definition.
+	AllowAnchorRedefinition bool
+
+	// AllowAnchorNoValue allows anchors with implicit null value
+	// like `foo: &bar`.
+	AllowAnchorNoValue bool
+
+	// AllowEmptyArrayItems allows empty items in sequences like `- `.
+	// Empty items of non-pointer item types are dropped by the decoder
+	// instead of becoming zero value items.
+	AllowEmptyArrayItems bool
+
+	// StrictKeyOrder requires the keys of mappings of struct types to appear
+	// in the order of the struct fields (see WithStrictKeyOrder).
+	StrictKeyOrder bool
+
+	// MapKeyConvention requires the keys of maps with string keys to follow
+	// the naming convention (see WithMapKeyConvention).
+	// Any key is allowed if zero.
+	MapKeyConvention NamingConvention
+}
+
+// WithRules makes ValidateType and the loader apply rules instead of
+// the default rules. Options WithStrictKeyOrder, WithYAMLTagConvention and
+// WithMapKeyConvention take precedence over the respective fields of rules
+// regardless of the order of the options.
+func WithRules(rules Rules) Option {
+	return func(o *options) { o.rules = rules }
+}
+
+// mergedMappings returns the mappings merged into mapping node
+// by merge keys.
+func mergedMappings(node *yaml.Node) []*yaml.Node {
+	var merged []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Tag != "!!merge" {
+			continue
+		}
+		v := resolveAlias(node.Content[i+1])
+		switch v.Kind {
+		case yaml.MappingNode:
+			merged = append(merged, v)
+		case yaml.SequenceNode:
+			for _, n := range v.Content {
+				if n = resolveAlias(n); n.Kind == yaml.MappingNode {
+					merged = append(merged, n)
+				}
+			}
+		}
+	}
+	return merged
+}
+
+// zeroNullItems replaces the null items of sequences in node of type tp
+// by nodes decoding to the zero value of the item type since yaml.v3 drops
+// null items of types that aren't nilable (see Rules.AllowNullOnNonPointer).
+// Empty items aren't replaced (see Rules.AllowEmptyArrayItems).
+// Returns a function restoring the replaced items.
+// Assumes that tp has already been validated using ValidateType.
+func zeroNullItems(tp reflect.Type, node *yaml.Node) (restore func()) {
+	var replaced []func()
+	var walk func(tp reflect.Type, node *yaml.Node)
+	var walkFields func(tp reflect.Type, node *yaml.Node)
+	walk = func(tp reflect.Type, node *yaml.Node) {
+		for tp.Kind() == reflect.Pointer {
+			tp = tp.Elem()
+		}
+		if t, ok := secretValueType(tp); ok {
+			tp = t
+		}
+		node = resolveAlias(node)
+		if implementsInterface[encoding.TextUnmarshaler](tp) ||
+			implementsInterface[yaml.Unmarshaler](tp) {
+			return
+		}
+		switch tp.Kind() {
+		case reflect.Struct:
+			if node.Kind == yaml.MappingNode {
+				walkFields(tp, node)
+			}
+		case reflect.Slice, reflect.Array:
+			if node.Kind != yaml.SequenceNode {
+				return
+			}
+			for i, item := range node.Content {
+				n := resolveAlias(item)
+				if n.Tag != "!!null" || n.Value == "" {
+					walk(tp.Elem(), item)
+					continue
+				}
+				if z := zeroNode(tp.Elem()); z != nil {
+					node.Content[i] = z
+					replaced = append(replaced, func() { node.Content[i] = item })
+				}
+			}
+		case reflect.Map:
+			if node.Kind != yaml.MappingNode {
+				return
+			}
+			for _, m := range append([]*yaml.Node{node}, mergedMappings(node)...) {
+				for i := 0; i < len(m.Content); i += 2 {
+					if m.Content[i].Tag != "!!merge" {
+						walk(tp.Elem(), m.Content[i+1])
+					}
+				}
+			}
+		}
+	}
+	walkFields = func(tp reflect.Type, node *yaml.Node) {
+		info := getStructInfo(tp)
+		for _, f := range info.Fields {
+			switch {
+			case f.YAMLTag == "-":
+			case f.Anonymous:
+				embedded := f.Type
+				if embedded.Kind() == reflect.Pointer {
+					embedded = embedded.Elem()
+				}
+				if isPlainStruct(embedded) {
+					walkFields(embedded, node)
+					continue
+				}
+				if embedded.Kind() != reflect.Map {
+					continue
+				}
+				for i := 0; i < len(node.Content); i += 2 {
+					if _, ok := info.Keys[node.Content[i].Value]; !ok &&
+						node.Content[i].Tag != "!!merge" {
+						walk(embedded.Elem(), node.Content[i+1])
+					}
+				}
+			default:
+				if n := findContentNodeByTag(node, f.YAMLTag); n != nil {
+					walk(f.Type, n)
+				}
+			}
+		}
+	}
+	walk(tp, node)
+	return func() {
+		for _, r := range replaced {
+			r()
+		}
+	}
+}
+
+// zeroNode returns a node decoding to the zero value of tp, or nil
+// if tp is nilable or implements encoding.TextUnmarshaler or yaml.Unmarshaler.
+func zeroNode(tp reflect.Type) *yaml.Node {
+	if implementsInterface[encoding.TextUnmarshaler](tp) ||
+		implementsInterface[yaml.Unmarshaler](tp) {
+		return nil
+	}
+	if tp == typeTimeDuration {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "0s"}
+	}
+	switch tp.Kind() {
+	case reflect.Struct:
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	case reflect.Array:
+		return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	case reflect.String:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str"}
+	case reflect.Bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "false"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: "0"}
+	case reflect.Float32, reflect.Float64:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: "0"}
+	}
+	return nil
+}
@@ -0,0 +1,403 @@
+package yamagiconf_test
+
+import (
+	"encoding/json"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+func loadWithRules[T any](src string, rules yamagiconf.Rules) (T, error) {
+	var c T
+	err := yamagiconf.Load(src, &c, yamagiconf.WithRules(rules))
+	return c, err
+}
+
+func TestRulesDefault(t *testing.T) {
+	type TestConfig struct {
+		Flag bool `yaml:"flag"`
+	}
+	_, err := loadWithRules[TestConfig]("flag: yes\n", yamagiconf.Rules{})
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLBadBoolLiteral)
+}
+
+func TestRulesAllowIntUint(t *testing.T) {
+	type TestConfig struct {
+		Int  int   `yaml:"int"`
+		Uint *uint `yaml:"uint" env:"RULES_TEST_UINT"`
+	}
+	require.ErrorIs(t, yamagiconf.ValidateType[TestConfig](),
+		yamagiconf.ErrTypeUnsupported)
+
+	t.Setenv("RULES_TEST_UINT", "42")
+	c, err := loadWithRules[TestConfig]("int: -1\nuint: null\n",
+		yamagiconf.Rules{AllowIntUint: true})
+	require.NoError(t, err)
+	require.Equal(t, -1, c.Int)
+	require.Equal(t, uint(42), *c.Uint)
+}
+
+func TestRulesYAMLTagConvention(t *testing.T) {
+	type TestConfig struct {
+		MaxConns int32 `yaml:"maxConns"`
+	}
+	err := yamagiconf.ValidateType[TestConfig](yamagiconf.WithRules(
+		yamagiconf.Rules{YAMLTagConvention: yamagiconf.NamingKebabCase},
+	))
+	require.ErrorIs(t, err, yamagiconf.ErrTypeYAMLTagConvention)
+	require.Equal(t, `at TestConfig.MaxConns: yaml struct tag doesn't `+
+		`follow the naming convention: "maxConns" isn't kebab-case, `+
+		`use "max-conns"`, err.Error())
+}
+
+func TestRulesAllowBoolVariants(t *testing.T) {
+	type TestConfig struct {
+		A bool `yaml:"a"`
+		B bool `yaml:"b"`
+	}
+	_, err := LoadSrc[TestConfig]("a: yes\nb: Off\n")
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLBadBoolLiteral)
+
+	c, err := loadWithRules[TestConfig]("a: yes\nb: Off\n",
+		yamagiconf.Rules{AllowBoolVariants: true})
+	require.NoError(t, err)
+	require.True(t, c.A)
+	require.False(t, c.B)
+}
+
+func TestRulesAllowNullVariants(t *testing.T) {
+	type TestConfig struct {
+		A *string `yaml:"a"`
+		B *string `yaml:"b"`
+		C string  `yaml:"c"`
+	}
+	_, err := LoadSrc[TestConfig]("a: ~\nb: NULL\nc: nULL\n")
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLBadNullLiteral)
+
+	c, err := loadWithRules[TestConfig]("a: ~\nb: NULL\nc: nULL\n",
+		yamagiconf.Rules{AllowNullVariants: true})
+	require.NoError(t, err)
+	require.Nil(t, c.A)
+	require.Nil(t, c.B)
+	require.Equal(t, "nULL", c.C)
+
+	_, err = loadWithRules[TestConfig]("a: ~\nb: NULL\nc: Null\n",
+		yamagiconf.Rules{AllowNullVariants: true})
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLNullOnNonPointer)
+}
+
+func TestRulesAllowNullOnNonPointer(t *testing.T) {
+	type TestConfig struct {
+		A string `yaml:"a"`
+		B int32  `yaml:"b"`
+		C bool   `yaml:"c"`
+	}
+	_, err := LoadSrc[TestConfig]("a: null\nb: null\nc: null\n")
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLNullOnNonPointer)
+
+	c, err := loadWithRules[TestConfig]("a: null\nb: null\nc: null\n",
+		yamagiconf.Rules{AllowNullOnNonPointer: true})
+	require.NoError(t, err)
+	require.Zero(t, c)
+
+	t.Run("struct", func(t *testing.T) {
+		type Inner struct {
+			X int32 `yaml:"x"`
+		}
+		type TestConfig struct {
+			Inner Inner `yaml:"inner"`
+		}
+		c, err := loadWithRules[TestConfig]("inner: null\n",
+			yamagiconf.Rules{AllowNullOnNonPointer: true})
+		require.NoError(t, err)
+		require.Zero(t, c)
+	})
+
+	t.Run("items", func(t *testing.T) {
+		type Inner struct {
+			X int32 `yaml:"x"`
+		}
+		type TestConfig struct {
+			Strings   []string           `yaml:"strings"`
+			Durations []time.Duration    `yaml:"durations"`
+			Structs   []Inner            `yaml:"structs"`
+			Array     [3]bool            `yaml:"array"`
+			Maps      []map[string]Inner `yaml:"maps"`
+		}
+		c, err := loadWithRules[TestConfig](`strings: [a, null, b]
+durations: [1s, null]
+structs:
+  - null
+  - x: 1
+array: [true, null, true]
+maps:
+  - a: {x: 1}
+    b: {x: 2}
+  - {}
+`, yamagiconf.Rules{AllowNullOnNonPointer: true})
+		require.NoError(t, err)
+		require.Equal(t, TestConfig{
+			Strings:   []string{"a", "", "b"},
+			Durations: []time.Duration{time.Second, 0},
+			Structs:   []Inner{{}, {X: 1}},
+			Array:     [3]bool{true, false, true},
+			Maps:      []map[string]Inner{{"a": {X: 1}, "b": {X: 2}}, {}},
+		}, c)
+	})
+
+	t.Run("items_text_unmarshaler", func(t *testing.T) {
+		type TestConfig struct {
+			Addrs []netip.Addr `yaml:"addrs"`
+		}
+		_, err := loadWithRules[TestConfig]("addrs: [127.0.0.1, null]\n",
+			yamagiconf.Rules{AllowNullOnNonPointer: true})
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLNullOnNonPointer)
+		require.Equal(t, `at 1:20: "addrs" (TestConfig.Addrs[1]): `+
+			yamagiconf.ErrYAMLNullOnNonPointer.Error(), err.Error())
+	})
+}
+
+func TestRulesAllowYAMLTags(t *testing.T) {
+	type TestConfig struct {
+		A string `yaml:"a"`
+	}
+	_, err := LoadSrc[TestConfig]("a: !!str 42\n")
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLTagUsed)
+
+	c, err := loadWithRules[TestConfig]("a: !!str 42\n",
+		yamagiconf.Rules{AllowYAMLTags: true})
+	require.NoError(t, err)
+	require.Equal(t, "42", c.A)
+}
+
+func TestRulesAllowMergeKeys(t *testing.T) {
+	type Server struct {
+		Host string `yaml:"host"`
+		Port uint16 `yaml:"port"`
+	}
+	type TestConfig struct {
+		Base    Server `yaml:"base"`
+		Primary Server `yaml:"primary"`
+		Backup  Server `yaml:"backup"`
+	}
+	const src = `base: &base
+  host: localhost
+  port: 8080
+primary:
+  <<: *base
+backup:
+  <<: [*base]
+  port: 8081
+`
+	_, err := LoadSrc[TestConfig](src)
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLMergeKey)
+
+	c, err := loadWithRules[TestConfig](src,
+		yamagiconf.Rules{AllowMergeKeys: true})
+	require.NoError(t, err)
+	require.Equal(t, Server{Host: "localhost", Port: 8080}, c.Primary)
+	require.Equal(t, Server{Host: "localhost", Port: 8081}, c.Backup)
+
+	t.Run("unknown merged key", func(t *testing.T) {
+		type TestConfig struct {
+			Primary Server `yaml:"primary"`
+			Backup  Server `yaml:"backup"`
+		}
+		_, err := loadWithRules[TestConfig](`primary: &p
+  host: localhost
+  port: 8080
+backup:
+  <<: *p
+  port: 8081
+`, yamagiconf.Rules{AllowMergeKeys: true})
+		require.NoError(t, err)
+
+		_, err = loadWithRules[TestConfig](`primary:
+  host: localhost
+  port: 8080
+backup:
+  <<: {host: localhost, unknown: 1}
+  port: 8081
+`, yamagiconf.Rules{AllowMergeKeys: true})
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLMalformed)
+	})
+}
+
+func TestRulesForbidAnchors(t *testing.T) {
+	type TestConfig struct {
+		A string `yaml:"a"`
+		B string `yaml:"b"`
+	}
+	_, err := loadWithRules[TestConfig]("a: &a x\nb: *a\n",
+		yamagiconf.Rules{ForbidAnchors: true})
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLAnchorUsed)
+	require.Equal(t, "at 1:4: avoid using YAML anchors and aliases", err.Error())
+}
+
+func TestRulesAllowUnusedAnchors(t *testing.T) {
+	type TestConfig struct {
+		A string `yaml:"a"`
+	}
+	_, err := LoadSrc[TestConfig]("a: &a x\n")
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLAnchorUnused)
+
+	c, err := loadWithRules[TestConfig]("a: &a x\n",
+		yamagiconf.Rules{AllowUnusedAnchors: true})
+	require.NoError(t, err)
+	require.Equal(t, "x", c.A)
+}
+
+func TestRulesAllowAnchorRedefinition(t *testing.T) {
+	type TestConfig struct {
+		A string `yaml:"a"`
+		B string `yaml:"b"`
+		C string `yaml:"c"`
+		D string `yaml:"d"`
+	}
+	const src = "a: &x first\nb: *x\nc: &x second\nd: *x\n"
+	_, err := LoadSrc[TestConfig](src)
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLAnchorRedefined)
+
+	c, err := loadWithRules[TestConfig](src,
+		yamagiconf.Rules{AllowAnchorRedefinition: true})
+	require.NoError(t, err)
+	require.Equal(t, TestConfig{A: "first", B: "first", C: "second", D: "second"}, c)
+}
+
+func TestRulesAllowAnchorNoValue(t *testing.T) {
+	type TestConfig struct {
+		A *string `yaml:"a"`
+		B *string `yaml:"b"`
+	}
+	const src = "a: &x\nb: *x\n"
+	_, err := LoadSrc[TestConfig](src)
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLAnchorNoValue)
+
+	c, err := loadWithRules[TestConfig](src,
+		yamagiconf.Rules{AllowAnchorNoValue: true})
+	require.NoError(t, err)
+	require.Nil(t, c.A)
+	require.Nil(t, c.B)
+}
+
+func TestRulesAllowEmptyArrayItems(t *testing.T) {
+	type TestConfig struct {
+		A []*string `yaml:"a"`
+	}
+	_, err := LoadSrc[TestConfig]("a:\n  -\n  - x\n")
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLEmptyArrayItem)
+
+	c, err := loadWithRules[TestConfig]("a:\n  -\n  - x\n",
+		yamagiconf.Rules{AllowEmptyArrayItems: true})
+	require.NoError(t, err)
+	require.Equal(t, []*string{nil, PtrTo("x")}, c.A)
+
+	t.Run("dropped", func(t *testing.T) {
+		type TestConfig struct {
+			A []string `yaml:"a"`
+		}
+		c, err := loadWithRules[TestConfig]("a:\n  -\n  - x\n",
+			yamagiconf.Rules{AllowEmptyArrayItems: true})
+		require.NoError(t, err)
+		require.Equal(t, []string{"x"}, c.A)
+	})
+}
+
+func TestRulesStrictKeyOrder(t *testing.T) {
+	type TestConfig struct {
+		A string `yaml:"a"`
+		B string `yaml:"b"`
+	}
+	_, err := loadWithRules[TestConfig]("b: b\na: a\n",
+		yamagiconf.Rules{StrictKeyOrder: true})
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLKeyOrder)
+
+	// Dedicated options compose with WithRules regardless of their order.
+	var c TestConfig
+	err = yamagiconf.Load("b: b\na: a\n", &c,
+		yamagiconf.WithStrictKeyOrder(),
+		yamagiconf.WithRules(yamagiconf.Rules{AllowBoolVariants: true}))
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLKeyOrder)
+	err = yamagiconf.Load("b: b\na: a\n", &c,
+		yamagiconf.WithRules(yamagiconf.Rules{AllowBoolVariants: true}),
+		yamagiconf.WithStrictKeyOrder())
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLKeyOrder)
+}
+
+func TestRulesMapKeyConvention(t *testing.T) {
+	type TestConfig struct {
+		Labels map[string]string `yaml:"labels"`
+	}
+	_, err := loadWithRules[TestConfig]("labels:\n  team-name: core\n",
+		yamagiconf.Rules{MapKeyConvention: yamagiconf.NamingSnakeCase})
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLKeyConvention)
+}
+
+func TestRulesOutputs(t *testing.T) {
+	type TestConfig struct {
+		Count int               `yaml:"count"`
+		Size  uint              `yaml:"size"`
+		Index map[int]string    `yaml:"index"`
+		Sizes map[string]uint64 `yaml:"sizes"`
+	}
+	opt := yamagiconf.WithRules(yamagiconf.Rules{AllowIntUint: true})
+	c := TestConfig{Count: -1, Size: 2, Index: map[int]string{1: "a"}}
+
+	_, err := yamagiconf.Marshal(&c)
+	require.ErrorIs(t, err, yamagiconf.ErrTypeUnsupported)
+
+	b, err := yamagiconf.Marshal(&c, opt)
+	require.NoError(t, err)
+	var loaded TestConfig
+	require.NoError(t, yamagiconf.Load(b, &loaded, opt))
+	require.Equal(t, c, loaded)
+
+	var dump strings.Builder
+	require.NoError(t, yamagiconf.DumpEffective(&c, nil, &dump, opt))
+	require.Equal(t, string(b), dump.String())
+
+	schema, err := yamagiconf.JSONSchema[TestConfig](opt)
+	require.NoError(t, err)
+	var s map[string]any
+	require.NoError(t, json.Unmarshal(schema, &s))
+	props := s["properties"].(map[string]any)
+	require.Equal(t, "integer", props["count"].(map[string]any)["type"])
+	require.Equal(t, float64(0), props["size"].(map[string]any)["minimum"])
+	require.Equal(t, map[string]any{"pattern": `^-?[0-9]+$`},
+		props["index"].(map[string]any)["propertyNames"])
+
+	require.NoError(t, yamagiconf.Load(yamagiconf.Template[TestConfig](opt),
+		new(TestConfig), opt))
+
+	_, err = yamagiconf.Docs[TestConfig](yamagiconf.DocsFormatMarkdown)
+	require.ErrorIs(t, err, yamagiconf.ErrTypeUnsupported)
+	_, err = yamagiconf.Docs[TestConfig](yamagiconf.DocsFormatMarkdown, opt)
+	require.NoError(t, err)
+}
+
+func TestRulesDocsRestrictions(t *testing.T) {
+	type TestConfig struct {
+		Name string `yaml:"name"`
+	}
+	d, err := yamagiconf.Docs[TestConfig](yamagiconf.DocsFormatMarkdown,
+		yamagiconf.WithRules(yamagiconf.Rules{
+			AllowMergeKeys:   true,
+			ForbidAnchors:    true,
+			StrictKeyOrder:   true,
+			MapKeyConvention: yamagiconf.NamingKebabCase,
+		}))
+	require.NoError(t, err)
+	s := string(d)
+	require.NotContains(t, s, "Merge keys")
+	require.NotContains(t, s, "Anchors must")
+	require.Contains(t, s, "- Anchors and aliases are not allowed.\n")
+	require.Contains(t, s, "- Keys must appear in the order of this table.\n")
+	require.Contains(t, s,
+		"- Keys of maps with string keys must be kebab-case.\n")
+	require.Contains(t, s, "- Booleans must be either `true` or `false`")
+}
@@ -0,0 +1,278 @@
+package yamagiconf
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Secret wraps a secret value of type T such as a password or a token.
+// A Secret is loaded exactly like a value of type T from both YAML and
+// `env` struct tags but its value is never revealed by String, GoString,
+// Format, MarshalJSON, MarshalYAML and LogValue, which all produce
+// "[REDACTED]". Errors of the loader don't contain the raw text of secrets.
+// Use Value to access the secret value.
+//
+// go-playground/validator struct tags on Secret fields apply to the Secret,
+// not the wrapped value, use `validate:"required"` or a Validate method instead.
+type Secret[T any] struct{ value T }
+
+// NewSecret returns a Secret wrapping v.
+func NewSecret[T any](v T) Secret[T] { return Secret[T]{value: v} }
+
+// Value returns the secret value.
+func (s Secret[T]) Value() T { return s.value }
+
+// String returns "[REDACTED]".
+func (s Secret[T]) String() string { return Redacted }
+
+// GoString returns "[REDACTED]".
+func (s Secret[T]) GoString() string { return Redacted }
+
+// Format writes "[REDACTED]" for any verb and flags.
+func (s Secret[T]) Format(f fmt.State, verb rune) {
+	_, _ = io.WriteString(f, Redacted)
+}
+
+// MarshalJSON returns "[REDACTED]" as a JSON string.
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + Redacted + `"`), nil
+}
+
+// MarshalYAML returns "[REDACTED]".
+func (s Secret[T]) MarshalYAML() (any, error) { return Redacted, nil }
+
+// LogValue implements slog.LogValuer and returns "[REDACTED]".
+func (s Secret[T]) LogValue() slog.Value { return slog.StringValue(Redacted) }
+
+// UnmarshalYAML implements yaml.Unmarshaler decoding node as T.
+// Errors don't contain the value of node.
+func (s *Secret[T]) UnmarshalYAML(node *yaml.Node) error {
+	if err := node.Decode(&s.value); err != nil {
+		return &yaml.TypeError{Errors: []string{fmt.Sprintf(
+			"line %d: cannot unmarshal %s into secret %T",
+			node.Line, node.ShortTag(), s.value,
+		)}}
+	}
+	return nil
+}
+
+func (s *Secret[T]) valuePtr() any { return &s.value }
+
+var secretPkgPath = reflect.TypeOf(Secret[string]{}).PkgPath()
+
+// secretValueType returns the type of the value wrapped by tp
+// and true if tp is a Secret type.
+func secretValueType(tp reflect.Type) (reflect.Type, bool) {
+	if tp.Kind() != reflect.Struct || tp.PkgPath() != secretPkgPath ||
+		!strings.HasPrefix(tp.Name(), "Secret[") {
+		return nil, false
+	}
+	return tp.Field(0).Type, true
+}
+
+// secretValueOf returns the settable value wrapped by Secret value v.
+// Assumes that v is addressable.
+func secretValueOf(v reflect.Value) reflect.Value {
+	p := v.Addr().Interface().(interface{ valuePtr() any }).valuePtr()
+	return reflect.ValueOf(p).Elem()
+}
+
+// isSecretField returns true if f is of a Secret type or is marked as secret
+// by a `secret` struct tag.
+func isSecretField(f reflect.StructField) bool {
+	if _, ok := secretValueType(f.Type); ok {
+		return true
+	}
+	_, ok := f.Tag.Lookup("secret")
+	return ok
+}
+
+// isEnvOnlySecretField returns true if f is tagged `secret:"env-only"`.
+func isEnvOnlySecretField(f reflect.StructField) bool {
+	return f.Tag.Get("secret") == "env-only"
+}
+
+// validateSecretTag returns an error if the `secret` struct tag of f
+// is invalid.
+func validateSecretTag(f reflect.StructField) error {
+	switch v, ok := f.Tag.Lookup("secret"); {
+	case !ok, v == "true":
+		return nil
+	case v == "env-only":
+		if f.Tag.Get("env") == "" {
+			return fmt.Errorf("%w: %q requires an env struct tag",
+				ErrTypeInvalidSecretTag, v)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %q, expected \"true\" or \"env-only\"",
+			ErrTypeInvalidSecretTag, v)
+	}
+}
+
+// validateEnvOnlySecretDefault returns an error if f is tagged
+// `secret:"env-only"` and has a `default` struct tag. The default value
+// would be a plaintext secret in the YAML document.
+func validateEnvOnlySecretDefault(f reflect.StructField) error {
+	if _, ok := f.Tag.Lookup("default"); ok && isEnvOnlySecretField(f) {
+		return fmt.Errorf("%w: \"env-only\" must not have a default value",
+			ErrTypeInvalidSecretTag)
+	}
+	return nil
+}
+
+// fieldEnvVar returns the name of the env var that overwrites field f
+// or "" if there is none. The value of secret fields can also be read from
+// the file at the path in env var <NAME>_FILE if env var <NAME> isn't set,
+// in which case isFile is true.
+func fieldEnvVar(f reflect.StructField) (name string, isFile bool) {
+	name = f.Tag.Get("env")
+	if name == "" {
+		return "", false
+	}
+	if _, ok := os.LookupEnv(name); ok {
+		return name, false
+	}
+	if isSecretField(f) {
+		if _, ok := os.LookupEnv(name + "_FILE"); ok {
+			return name + "_FILE", true
+		}
+	}
+	return "", false
+}
+
+// validateEnvOnlySecret returns an error if node of an env-only secret field
+// contains a value other than null or an empty string, collection or mapping.
+func validateEnvOnlySecret(yamlTag, path string, node *yaml.Node) error {
+	n := resolveAlias(node)
+	if n.Kind == yaml.ScalarNode && (n.Value == "" || n.Tag == "!!null") ||
+		n.Kind != yaml.ScalarNode && len(n.Content) < 1 {
+		return nil
+	}
+	return fmt.Errorf("at %d:%d: %q (%s): %w",
+		node.Line, node.Column, yamlTag, path, ErrYAMLPlaintextSecret)
+}
+
+// decodeDocument decodes document node into config.
+// Unlike the errors of yaml.v3 and encoding.TextUnmarshaler implementations,
+// errors decoding the values of secret fields don't contain the value.
+// Assumes that the type of config has already been validated using ValidateType.
+func decodeDocument(o *options, document *yaml.Node, config any) error {
+	if len(document.Content) > 0 {
+		tp := reflect.TypeOf(config).Elem()
+		if err := decodeSecretValues(tp, document.Content[0]); err != nil {
+			return err
+		}
+		if o.rules.AllowNullOnNonPointer {
+			defer zeroNullItems(tp, document.Content[0])()
+		}
+	}
+	return document.Decode(config)
+}
+
+// decodeSecretValues returns a redacted error if the value of any secret
+// field in node of type tp fails to decode.
+func decodeSecretValues(tp reflect.Type, node *yaml.Node) error {
+	for tp.Kind() == reflect.Pointer {
+		tp = tp.Elem()
+	}
+	node = resolveAlias(node)
+	if implementsInterface[encoding.TextUnmarshaler](tp) ||
+		implementsInterface[yaml.Unmarshaler](tp) {
+		return nil
+	}
+	switch tp.Kind() {
+	case reflect.Struct:
+		if node.Kind == yaml.MappingNode {
+			return decodeSecretFields(tp, node)
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for _, n := range node.Content {
+			if err := decodeSecretValues(tp.Elem(), n); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for _, m := range append([]*yaml.Node{node}, mergedMappings(node)...) {
+			for i := 0; i < len(m.Content); i += 2 {
+				if m.Content[i].Tag == "!!merge" {
+					continue
+				}
+				if err := decodeSecretValues(tp.Elem(), m.Content[i+1]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// decodeSecretFields is decodeSecretValues for the fields of struct type tp
+// including the fields of embedded inline structs and maps.
+func decodeSecretFields(tp reflect.Type, node *yaml.Node) error {
+	info := getStructInfo(tp)
+	for _, f := range info.Fields {
+		switch {
+		case f.YAMLTag == "-":
+		case f.Anonymous:
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if isPlainStruct(embedded) {
+				if err := decodeSecretFields(embedded, node); err != nil {
+					return err
+				}
+				continue
+			}
+			if embedded.Kind() != reflect.Map {
+				continue
+			}
+			for i := 0; i < len(node.Content); i += 2 {
+				if _, ok := info.Keys[node.Content[i].Value]; ok ||
+					node.Content[i].Tag == "!!merge" {
+					continue
+				}
+				err := decodeSecretValues(embedded.Elem(), node.Content[i+1])
+				if err != nil {
+					return err
+				}
+			}
+		default:
+			n := findContentNodeByTag(node, f.YAMLTag)
+			if n == nil {
+				continue
+			}
+			if !isSecretField(f.StructField) {
+				if err := decodeSecretValues(f.Type, n); err != nil {
+					return err
+				}
+				continue
+			}
+			tp := f.Type
+			if t, ok := secretValueType(tp); ok {
+				tp = t
+			}
+			if err := n.Decode(reflect.New(tp).Interface()); err != nil {
+				return &yaml.TypeError{Errors: []string{fmt.Sprintf(
+					"line %d: cannot unmarshal %s into secret %s",
+					n.Line, resolveAlias(n).ShortTag(), tp,
+				)}}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,411 @@
+package yamagiconf_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type SecretConfig struct {
+	Password yamagiconf.Secret[string]  `yaml:"password" validate:"required"`
+	PIN      yamagiconf.Secret[int32]   `yaml:"pin" env:"SECRET_TEST_PIN"`
+	Token    yamagiconf.Secret[*string] `yaml:"token" env:"SECRET_TEST_TOKEN"`
+	Enabled  yamagiconf.Secret[bool]    `yaml:"enabled"`
+	Key      yamagiconf.Secret[string]  `yaml:"key" default:"''"`
+}
+
+func TestSecret(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		c, err := LoadSrc[SecretConfig](`password: hunter2
+pin: 1234
+token: null
+enabled: true
+`)
+		require.NoError(t, err)
+		require.Equal(t, "hunter2", c.Password.Value())
+		require.Equal(t, int32(1234), c.PIN.Value())
+		require.Nil(t, c.Token.Value())
+		require.True(t, c.Enabled.Value())
+		require.Equal(t, "", c.Key.Value())
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("SECRET_TEST_PIN", "4321")
+		t.Setenv("SECRET_TEST_TOKEN", "tok")
+		c, err := LoadSrc[SecretConfig](`password: hunter2
+pin: 1234
+token: null
+enabled: false
+`)
+		require.NoError(t, err)
+		require.Equal(t, int32(4321), c.PIN.Value())
+		require.Equal(t, "tok", *c.Token.Value())
+	})
+
+	t.Run("redacted", func(t *testing.T) {
+		s := yamagiconf.NewSecret("hunter2")
+		c := SecretConfig{Password: s}
+		for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x"} {
+			require.NotContains(t, fmt.Sprintf(format, c), "hunter2", format)
+			require.NotContains(t, fmt.Sprintf(format, &c), "hunter2", format)
+		}
+		require.Equal(t, "[REDACTED]", fmt.Sprint(s))
+		require.Equal(t, "[REDACTED]", s.String())
+		require.Equal(t, "[REDACTED]", s.GoString())
+
+		j, err := json.Marshal(c)
+		require.NoError(t, err)
+		require.NotContains(t, string(j), "hunter2")
+		require.Contains(t, string(j), `"Password":"[REDACTED]"`)
+
+		y, err := yaml.Marshal(c)
+		require.NoError(t, err)
+		require.NotContains(t, string(y), "hunter2")
+		require.Contains(t, string(y), "password: '[REDACTED]'")
+
+		var b bytes.Buffer
+		slog.New(slog.NewTextHandler(&b, nil)).Info("config", "password", s)
+		require.NotContains(t, b.String(), "hunter2")
+		require.Contains(t, b.String(), "password=[REDACTED]")
+
+		b.Reset()
+		var pc SecretConfig
+		require.NoError(t, yamagiconf.Load("password: hunter2\npin: 1\n"+
+			"token: null\nenabled: false\n", &pc))
+		require.NoError(t, yamagiconf.DumpEffective(&pc, nil, &b))
+		require.NotContains(t, b.String(), "hunter2")
+	})
+
+	t.Run("err_env", func(t *testing.T) {
+		t.Setenv("SECRET_TEST_PIN", "hunter2")
+		_, err := LoadSrc[SecretConfig](`password: x
+pin: 1234
+token: null
+enabled: false
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrEnvInvalidVar)
+		require.NotContains(t, err.Error(), "hunter2")
+		require.Equal(t, "at SecretConfig.PIN: invalid env var "+
+			"SECRET_TEST_PIN: expected int32", err.Error())
+	})
+
+	t.Run("err_yaml", func(t *testing.T) {
+		_, err := LoadSrc[SecretConfig](`password: x
+pin: hunter2
+token: null
+enabled: false
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLMalformed)
+		require.NotContains(t, err.Error(), "hunter2")
+		require.Equal(t, "malformed YAML: yaml: unmarshal errors:\n"+
+			"  line 2: cannot unmarshal !!str into secret int32", err.Error())
+	})
+
+	t.Run("err_bool_literal", func(t *testing.T) {
+		_, err := LoadSrc[SecretConfig](`password: x
+pin: 1
+token: null
+enabled: yes
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLBadBoolLiteral)
+	})
+
+	t.Run("err_null", func(t *testing.T) {
+		_, err := LoadSrc[SecretConfig](`password: null
+pin: 1
+token: null
+enabled: false
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLNullOnNonPointer)
+	})
+
+	t.Run("err_validation", func(t *testing.T) {
+		_, err := LoadSrc[SecretConfig](`password: ''
+pin: 1
+token: null
+enabled: false
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrValidationTag)
+		require.Equal(t, `at 1:11: "password" violates validation rule: "required"`,
+			err.Error())
+	})
+
+	t.Run("err_validation_tagged", func(t *testing.T) {
+		type Server struct {
+			Pass string `yaml:"pass" secret:"true" validate:"min=20"`
+		}
+		type TestConfig struct {
+			Pass    string            `yaml:"pass" secret:"true" validate:"min=20"`
+			Servers []Server          `yaml:"servers" validate:"dive"`
+			Keys    map[string]string `yaml:"keys" secret:"true" validate:"dive,min=20"`
+			Name    string            `yaml:"name" validate:"min=20"`
+		}
+		_, err := LoadSrc[TestConfig](`pass: hunter2
+servers:
+  - pass: hunter2
+keys:
+  a: hunter2
+name: public
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrValidationTag)
+		require.NotContains(t, err.Error(), "hunter2")
+		require.Equal(t, `at 1:7: "pass" violates validation rule: "min=20", `+
+			`got [REDACTED]`+"\n"+
+			`at 3:11: "pass" violates validation rule: "min=20", `+
+			`got [REDACTED]`+"\n"+
+			`at 5:6: "keys" violates validation rule: "min=20", `+
+			`got [REDACTED]`+"\n"+
+			`at 6:7: "name" violates validation rule: "min=20", `+
+			`got "public"`, err.Error())
+	})
+
+	t.Run("err_tagged", func(t *testing.T) {
+		type Server struct {
+			Addr *netip.Addr `yaml:"addr" secret:"true"`
+		}
+		type Inline struct {
+			Code int32 `yaml:"code" secret:"true"`
+		}
+		type TestConfig struct {
+			Inline  `yaml:",inline"`
+			Addr    netip.Addr         `yaml:"addr" env:"SECRET_TEST_ADDR" secret:"true"`
+			PIN     int32              `yaml:"pin" env:"SECRET_TEST_TAGGED_PIN" secret:"true"`
+			Servers []Server           `yaml:"servers"`
+			Ports   map[string]*Server `yaml:"ports"`
+		}
+		src := func(addr, pin, code, server string) string {
+			return fmt.Sprintf("code: %s\naddr: %s\npin: %s\n"+
+				"servers:\n  - addr: %s\nports: {}\n", code, addr, pin, server)
+		}
+
+		for _, td := range []struct {
+			name   string
+			src    string
+			expect string
+		}{
+			{
+				name:   "text_unmarshaler",
+				src:    src("hunter2-secret", "1", "1", "127.0.0.1"),
+				expect: "line 2: cannot unmarshal !!str into secret netip.Addr",
+			},
+			{
+				name:   "int",
+				src:    src("127.0.0.1", "hunter2", "1", "127.0.0.1"),
+				expect: "line 3: cannot unmarshal !!str into secret int32",
+			},
+			{
+				name:   "inline",
+				src:    src("127.0.0.1", "1", "hunter2", "127.0.0.1"),
+				expect: "line 1: cannot unmarshal !!str into secret int32",
+			},
+			{
+				name:   "slice",
+				src:    src("127.0.0.1", "1", "1", "hunter2-secret"),
+				expect: "line 5: cannot unmarshal !!str into secret *netip.Addr",
+			},
+			{
+				name: "map",
+				src: "code: 1\naddr: 127.0.0.1\npin: 1\nservers: []\n" +
+					"ports:\n  a:\n    addr: hunter2-secret\n",
+				expect: "line 7: cannot unmarshal !!str into secret *netip.Addr",
+			},
+		} {
+			t.Run("yaml_"+td.name, func(t *testing.T) {
+				_, err := LoadSrc[TestConfig](td.src)
+				require.ErrorIs(t, err, yamagiconf.ErrYAMLMalformed)
+				require.NotContains(t, err.Error(), "hunter2")
+				require.Equal(t, "malformed YAML: yaml: unmarshal errors:\n  "+
+					td.expect, err.Error())
+			})
+		}
+
+		t.Run("env_text_unmarshaler", func(t *testing.T) {
+			t.Setenv("SECRET_TEST_ADDR", "hunter2-secret")
+			_, err := LoadSrc[TestConfig](src("127.0.0.1", "1", "1", "127.0.0.1"))
+			require.ErrorIs(t, err, yamagiconf.ErrEnvInvalidVar)
+			require.NotContains(t, err.Error(), "hunter2")
+			require.Equal(t, "at TestConfig.Addr: invalid env var "+
+				"SECRET_TEST_ADDR: expected netip.Addr", err.Error())
+		})
+
+		t.Run("env_int", func(t *testing.T) {
+			t.Setenv("SECRET_TEST_TAGGED_PIN", "hunter2")
+			_, err := LoadSrc[TestConfig](src("127.0.0.1", "1", "1", "127.0.0.1"))
+			require.ErrorIs(t, err, yamagiconf.ErrEnvInvalidVar)
+			require.NotContains(t, err.Error(), "hunter2")
+			require.Equal(t, "at TestConfig.PIN: invalid env var "+
+				"SECRET_TEST_TAGGED_PIN: expected int32", err.Error())
+		})
+
+		t.Run("validate", func(t *testing.T) {
+			type TestConfig struct {
+				Pass string `yaml:"pass" env:"SECRET_TEST_PASS" secret:"true" validate:"min=20"`
+			}
+			t.Setenv("SECRET_TEST_PASS", "hunter2")
+			_, err := LoadSrc[TestConfig]("pass: ''\n")
+			require.ErrorIs(t, err, yamagiconf.ErrValidationTag)
+			require.NotContains(t, err.Error(), "hunter2")
+
+			err = yamagiconf.Validate(TestConfig{Pass: "hunter2"})
+			require.ErrorIs(t, err, yamagiconf.ErrValidationTag)
+			require.NotContains(t, err.Error(), "hunter2")
+		})
+	})
+
+	t.Run("err_type", func(t *testing.T) {
+		type TestConfig struct {
+			Port yamagiconf.Secret[int] `yaml:"port"`
+		}
+		err := yamagiconf.ValidateType[TestConfig]()
+		require.ErrorIs(t, err, yamagiconf.ErrTypeUnsupported)
+		require.True(t, strings.HasPrefix(err.Error(), "at TestConfig.Port: "))
+	})
+}
+
+type EnvOnlyConfig struct {
+	Password string                     `yaml:"password" env:"ENV_ONLY_TEST_PASSWORD" secret:"env-only"`
+	Token    *string                    `yaml:"token" env:"ENV_ONLY_TEST_TOKEN" secret:"env-only"`
+	Key      yamagiconf.Secret[string]  `yaml:"key" env:"ENV_ONLY_TEST_KEY" secret:"env-only"`
+	Tagged   yamagiconf.Secret[*string] `yaml:"tagged" env:"ENV_ONLY_TEST_TAGGED"`
+}
+
+func TestEnvOnlySecret(t *testing.T) {
+	t.Run("placeholders", func(t *testing.T) {
+		t.Setenv("ENV_ONLY_TEST_PASSWORD", "hunter2")
+		t.Setenv("ENV_ONLY_TEST_TOKEN", "tok")
+		c, err := LoadSrc[EnvOnlyConfig](`password: ''
+token: null
+key: ""
+tagged: null
+`)
+		require.NoError(t, err)
+		require.Equal(t, "hunter2", c.Password)
+		require.Equal(t, "tok", *c.Token)
+		require.Equal(t, "", c.Key.Value())
+	})
+
+	t.Run("file", func(t *testing.T) {
+		dir := t.TempDir()
+		p := filepath.Join(dir, "key")
+		require.NoError(t, os.WriteFile(p, []byte("from-file\n"), 0o600))
+		t.Setenv("ENV_ONLY_TEST_KEY_FILE", p)
+		t.Setenv("ENV_ONLY_TEST_TAGGED_FILE", p)
+		// The env var takes precedence over the file.
+		t.Setenv("ENV_ONLY_TEST_PASSWORD", "from-env")
+		t.Setenv("ENV_ONLY_TEST_PASSWORD_FILE", p)
+
+		var c EnvOnlyConfig
+		prov, err := yamagiconf.LoadWithProvenance(`password: ''
+token: null
+key: ''
+tagged: null
+`, &c)
+		require.NoError(t, err)
+		require.Equal(t, "from-file", c.Key.Value())
+		require.Equal(t, "from-file", *c.Tagged.Value())
+		require.Equal(t, "from-env", c.Password)
+		require.Equal(t, "key: env ENV_ONLY_TEST_KEY_FILE (overrides 3:6)",
+			prov.Explain("key"))
+	})
+
+	t.Run("err_file", func(t *testing.T) {
+		t.Setenv("ENV_ONLY_TEST_KEY_FILE", filepath.Join(t.TempDir(), "nope"))
+		_, err := LoadSrc[EnvOnlyConfig](`password: ''
+token: null
+key: ''
+tagged: null
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrEnvInvalidVar)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	for _, td := range []struct {
+		name   string
+		src    string
+		expect string
+	}{
+		{
+			name: "string",
+			src: `password: hunter2
+token: null
+key: ''
+tagged: null
+`,
+			expect: `at 1:11: "password" (EnvOnlyConfig.Password): `,
+		},
+		{
+			name: "pointer",
+			src: `password: ''
+token: tok
+key: ''
+tagged: null
+`,
+			expect: `at 2:8: "token" (EnvOnlyConfig.Token): `,
+		},
+		{
+			name: "secret",
+			src: `password: ''
+token: null
+key: hunter2
+tagged: null
+`,
+			expect: `at 3:6: "key" (EnvOnlyConfig.Key): `,
+		},
+		{
+			name: "alias",
+			src: `password: &p hunter2
+token: null
+key: *p
+tagged: null
+`,
+			expect: `at 1:11: "password" (EnvOnlyConfig.Password): `,
+		},
+	} {
+		t.Run("err_"+td.name, func(t *testing.T) {
+			_, err := LoadSrc[EnvOnlyConfig](td.src)
+			require.ErrorIs(t, err, yamagiconf.ErrYAMLPlaintextSecret)
+			require.Equal(t, td.expect+yamagiconf.ErrYAMLPlaintextSecret.Error(),
+				err.Error())
+		})
+	}
+
+	t.Run("err_type_no_env", func(t *testing.T) {
+		type TestConfig struct {
+			Password string `yaml:"password" secret:"env-only"`
+		}
+		err := yamagiconf.ValidateType[TestConfig]()
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidSecretTag)
+		require.Equal(t, `at TestConfig.Password: invalid secret struct tag: `+
+			`"env-only" requires an env struct tag`, err.Error())
+	})
+
+	t.Run("err_type_default", func(t *testing.T) {
+		type TestConfig struct {
+			Name     string `yaml:"name"`
+			Password string `yaml:"password" env:"ENV_ONLY_TEST_DEFAULT" secret:"env-only" default:"''"`
+		}
+		t.Setenv("ENV_ONLY_TEST_DEFAULT", "hunter2")
+		err := yamagiconf.ValidateType[TestConfig]()
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidSecretTag)
+		require.Equal(t, `at TestConfig.Password: invalid secret struct tag: `+
+			`"env-only" must not have a default value`, err.Error())
+
+		// Loading with the key omitted reports the type error
+		// instead of a plaintext secret at 1:1.
+		_, err = LoadSrc[TestConfig]("name: x\n")
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidSecretTag)
+		require.NotErrorIs(t, err, yamagiconf.ErrYAMLPlaintextSecret)
+	})
+
+}
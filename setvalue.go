@@ -0,0 +1,344 @@
+package yamagiconf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetValue returns YAML document src with the value at yamlPath set to value.
+// yamlPath uses the syntax of Provenance, for example `server.port`,
+// `servers[1].host` or `labels[env]`. A missing key is added to its mapping
+// if it's the last element of the path. Keys of struct fields are inserted
+// in the order of the fields, map keys are appended.
+// value is encoded like by Marshal, use nil to set `null`.
+//
+// A scalar value replacing a scalar is written in place of the old one
+// leaving the rest of src byte for byte intact. Otherwise SetValue edits
+// the YAML node tree of src so that comments and the order of existing keys
+// are preserved but the document is re-indented by 2 spaces and blank lines
+// are removed.
+// The returned document is validated against T like by Load and
+// the error is returned if it fails to load.
+// Returns ErrPathNotFound if yamlPath doesn't exist in src or traverses
+// an alias.
+func SetValue[T any](
+	src []byte, yamlPath string, value any, opts ...Option,
+) ([]byte, error) {
+	o := newOptions(opts)
+	if err := validateType[T](o); err != nil {
+		return nil, err
+	}
+	return setValue[T](o, src, yamlPath, value)
+}
+
+func setValue[T any](
+	o *options, src []byte, yamlPath string, value any,
+) ([]byte, error) {
+	if len(src) == 0 {
+		return nil, ErrYAMLEmptyFile
+	}
+	var doc yaml.Node
+	if err := newDecoderYAML(src).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrYAMLMalformed, err)
+	}
+	path, err := parseYAMLPath(yamlPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.Content) < 1 {
+		return nil, ErrYAMLEmptyFile
+	}
+
+	var newValue *yaml.Node
+	if v := reflect.ValueOf(value); v.IsValid() {
+		if newValue, err = (nodeEncoder{}).encodeValue(yamlPath, v); err != nil {
+			return nil, err
+		}
+	} else {
+		newValue = newNullNode()
+	}
+
+	parent, i, err := findYAMLPath(doc.Content[0], path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrPathNotFound, yamlPath, err)
+	}
+	if i < 0 {
+		// Insert the missing key in the order of the struct fields.
+		key := path[len(path)-1]
+		var t T
+		tp := yamlPathType(reflect.TypeOf(t), path[:len(path)-1])
+		parent.Content = slices.Insert(parent.Content,
+			insertKeyIndex(tp, parent, key), newStringNode(key), newValue)
+	} else {
+		old := parent.Content[i]
+		if b, ok := spliceScalar(src, old, newValue, inFlowCollection(&doc, path)); ok {
+			if err := load(context.Background(), o, b, new(T), "", nil); err != nil {
+				return nil, err
+			}
+			return b, nil
+		}
+		newValue.Anchor = old.Anchor
+		newValue.HeadComment = old.HeadComment
+		newValue.LineComment = old.LineComment
+		newValue.FootComment = old.FootComment
+		parent.Content[i] = newValue
+	}
+
+	var b bytes.Buffer
+	if err := encodeYAML(&b, &doc); err != nil {
+		return nil, err
+	}
+	if err := load(context.Background(), o, b.Bytes(), new(T), "", nil); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// spliceScalar returns src with the bytes of scalar node old replaced by
+// scalar node value. inFlow must be true if old is part of a flow collection.
+// Returns false if old isn't a plain or quoted scalar on a single line
+// or value can't be written on a single line.
+func spliceScalar(src []byte, old, value *yaml.Node, inFlow bool) ([]byte, bool) {
+	if old.Kind != yaml.ScalarNode || value.Kind != yaml.ScalarNode ||
+		old.Anchor != "" || old.Style&^(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) != 0 {
+		return nil, false
+	}
+	start := lineOffset(src, old.Line)
+	if start < 0 {
+		return nil, false
+	}
+	for c := 1; c < old.Column; c++ {
+		_, size := utf8.DecodeRune(src[start:])
+		if size == 0 || src[start] == '\n' {
+			return nil, false
+		}
+		start += size
+	}
+	end := scalarEnd(src[start:], old)
+	if end < 0 {
+		return nil, false
+	}
+	end += start
+
+	if inFlow && value.Style == 0 &&
+		strings.ContainsAny(value.Value, ",[]{}") {
+		value.Style = yaml.DoubleQuotedStyle
+	}
+	var b bytes.Buffer
+	if err := encodeYAML(&b, value); err != nil {
+		return nil, false
+	}
+	v := bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+	if bytes.ContainsAny(v, "\n\r") {
+		return nil, false
+	}
+	return slices.Concat(src[:start], v, src[end:]), true
+}
+
+// lineOffset returns the offset of the beginning of 1-based line in src
+// or -1 if src has fewer lines.
+func lineOffset(src []byte, line int) int {
+	offset := 0
+	for l := 1; l < line; l++ {
+		i := bytes.IndexByte(src[offset:], '\n')
+		if i < 0 {
+			return -1
+		}
+		offset += i + 1
+	}
+	return offset
+}
+
+// scalarEnd returns the length of the source of scalar node n at the
+// beginning of s or -1 if it doesn't end on the same line.
+func scalarEnd(s []byte, n *yaml.Node) int {
+	lineEnd := bytes.IndexAny(s, "\r\n")
+	if lineEnd < 0 {
+		lineEnd = len(s)
+	}
+	switch n.Style {
+	case yaml.DoubleQuotedStyle:
+		for i := 1; i < lineEnd; i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+	case yaml.SingleQuotedStyle:
+		for i := 1; i < lineEnd; i++ {
+			if s[i] == '\'' {
+				if i+1 < lineEnd && s[i+1] == '\'' {
+					i++
+					continue
+				}
+				return i + 1
+			}
+		}
+	default:
+		// Plain scalars on a single line are written verbatim.
+		if len(n.Value) <= lineEnd && string(s[:len(n.Value)]) == n.Value {
+			return len(n.Value)
+		}
+	}
+	return -1
+}
+
+// inFlowCollection reports whether any collection on path in doc
+// is written in flow style. Assumes path exists in doc.
+func inFlowCollection(doc *yaml.Node, path []string) bool {
+	node := doc.Content[0]
+	for _, elem := range path {
+		if node.Style&yaml.FlowStyle != 0 {
+			return true
+		}
+		parent, i, err := findYAMLPath(node, []string{elem})
+		if err != nil || i < 0 {
+			return false
+		}
+		node = parent.Content[i]
+	}
+	return false
+}
+
+// yamlPathType returns the type of the value at path in a value of type tp
+// or nil if path doesn't refer to a field, item or map value of tp.
+func yamlPathType(tp reflect.Type, path []string) reflect.Type {
+	for _, elem := range path {
+		tp = derefType(tp)
+		switch tp.Kind() {
+		case reflect.Struct:
+			if !isPlainStruct(tp) {
+				return nil
+			}
+			index := findFieldIndexByYAMLTag(tp, elem)
+			if index == nil {
+				return nil // Key of an embedded inline map.
+			}
+			tp = tp.FieldByIndex(index).Type
+		case reflect.Slice, reflect.Array, reflect.Map:
+			tp = tp.Elem()
+		default:
+			return nil
+		}
+	}
+	return tp
+}
+
+// insertKeyIndex returns the index in the content of mapping node at which
+// key must be inserted to keep the keys in the order of the fields of struct
+// type tp. Returns the end of the content if tp isn't a struct type
+// or key isn't a key of one of its fields.
+func insertKeyIndex(tp reflect.Type, node *yaml.Node, key string) int {
+	if tp == nil || !isPlainStruct(derefType(tp)) {
+		return len(node.Content)
+	}
+	order := getStructInfo(derefType(tp)).Order
+	x := slices.Index(order, key)
+	if x < 0 {
+		return len(node.Content)
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if slices.Index(order, node.Content[i].Value) > x {
+			return i
+		}
+	}
+	return len(node.Content)
+}
+
+// derefType returns the type of the value referenced by pointer type tp
+// or wrapped by Secret type tp.
+func derefType(tp reflect.Type) reflect.Type {
+	for {
+		if t, ok := secretValueType(tp); ok {
+			tp = t
+		} else if tp.Kind() == reflect.Pointer {
+			tp = tp.Elem()
+		} else {
+			return tp
+		}
+	}
+}
+
+// parseYAMLPath splits a path like `servers[1].host` or `labels[env]`
+// into its elements.
+func parseYAMLPath(path string) ([]string, error) {
+	var elems []string
+	for s := path; s != ""; {
+		switch {
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: %s: missing ']'", ErrPathNotFound, path)
+			}
+			elems, s = append(elems, s[1:end]), s[end+1:]
+			if strings.HasPrefix(s, ".") {
+				s = s[1:]
+			}
+		default:
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("%w: %s: empty key", ErrPathNotFound, path)
+			}
+			elems, s = append(elems, s[:end]), s[end:]
+			if strings.HasPrefix(s, ".") {
+				s = s[1:]
+			}
+		}
+	}
+	if len(elems) < 1 {
+		return nil, fmt.Errorf("%w: empty path", ErrPathNotFound)
+	}
+	return elems, nil
+}
+
+// findYAMLPath returns the collection node containing the node at path and
+// its index in the content of the collection. The index is -1 if the last
+// element of path is a key missing in the mapping.
+func findYAMLPath(node *yaml.Node, path []string) (*yaml.Node, int, error) {
+	for depth, elem := range path {
+		if node.Kind == yaml.AliasNode {
+			return nil, 0, fmt.Errorf("alias at %d:%d", node.Line, node.Column)
+		}
+		last := depth == len(path)-1
+		i := -1
+		switch node.Kind {
+		case yaml.MappingNode:
+			for k := 0; k < len(node.Content); k += 2 {
+				if node.Content[k].Value == elem {
+					i = k + 1
+					break
+				}
+			}
+			if i < 0 && !last {
+				return nil, 0, fmt.Errorf("missing key %q", elem)
+			}
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(elem)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				return nil, 0, fmt.Errorf("invalid index %q", elem)
+			}
+			i = index
+		default:
+			return nil, 0, fmt.Errorf("no mapping or sequence at %d:%d",
+				node.Line, node.Column)
+		}
+		if last {
+			return node, i, nil
+		}
+		node = node.Content[i]
+	}
+	panic("unreachable")
+}
@@ -0,0 +1,375 @@
+package yamagiconf_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+type SetValueConfig struct {
+	Server  SetValueServer    `yaml:"server"`
+	Servers []SetValueServer  `yaml:"servers"`
+	Labels  map[string]string `yaml:"labels"`
+	Proxy   *string           `yaml:"proxy"`
+	Timeout time.Duration     `yaml:"timeout" default:"30s"`
+}
+
+type SetValueServer struct {
+	Host string `yaml:"host"`
+	Port uint16 `yaml:"port" validate:"min=1"`
+}
+
+const setValueTestSrc = `# Service configuration.
+server:
+  host: localhost # The host name.
+  # The port to listen on.
+  port: 8080
+servers:
+    - host: a.local
+      port: 81
+labels:
+    env: prod
+proxy: null
+`
+
+func TestSetValue(t *testing.T) {
+	for _, td := range []struct {
+		name   string
+		path   string
+		value  any
+		expect string
+	}{
+		{
+			name:  "port",
+			path:  "server.port",
+			value: uint16(9090),
+			expect: `# Service configuration.
+server:
+  host: localhost # The host name.
+  # The port to listen on.
+  port: 9090
+servers:
+    - host: a.local
+      port: 81
+labels:
+    env: prod
+proxy: null
+`,
+		},
+		{
+			name:  "comment_kept",
+			path:  "server.host",
+			value: "true",
+			expect: `# Service configuration.
+server:
+  host: "true" # The host name.
+  # The port to listen on.
+  port: 8080
+servers:
+    - host: a.local
+      port: 81
+labels:
+    env: prod
+proxy: null
+`,
+		},
+		{
+			name:  "slice_item",
+			path:  "servers[0].host",
+			value: "b.local",
+			expect: `# Service configuration.
+server:
+  host: localhost # The host name.
+  # The port to listen on.
+  port: 8080
+servers:
+    - host: b.local
+      port: 81
+labels:
+    env: prod
+proxy: null
+`,
+		},
+		{
+			name:  "struct",
+			path:  "servers[0]",
+			value: SetValueServer{Host: "c.local", Port: 82},
+			expect: `# Service configuration.
+server:
+  host: localhost # The host name.
+  # The port to listen on.
+  port: 8080
+servers:
+  - host: c.local
+    port: 82
+labels:
+  env: prod
+proxy: null
+`,
+		},
+		{
+			name:  "new_map_key",
+			path:  "labels[team]",
+			value: "core",
+			expect: `# Service configuration.
+server:
+  host: localhost # The host name.
+  # The port to listen on.
+  port: 8080
+servers:
+  - host: a.local
+    port: 81
+labels:
+  env: prod
+  team: core
+proxy: null
+`,
+		},
+		{
+			name:  "nil_to_value",
+			path:  "proxy",
+			value: "http://proxy",
+			expect: `# Service configuration.
+server:
+  host: localhost # The host name.
+  # The port to listen on.
+  port: 8080
+servers:
+    - host: a.local
+      port: 81
+labels:
+    env: prod
+proxy: http://proxy
+`,
+		},
+		{
+			name:  "missing_default_field",
+			path:  "timeout",
+			value: time.Minute,
+			expect: `# Service configuration.
+server:
+  host: localhost # The host name.
+  # The port to listen on.
+  port: 8080
+servers:
+  - host: a.local
+    port: 81
+labels:
+  env: prod
+proxy: null
+timeout: 1m0s
+`,
+		},
+	} {
+		t.Run(td.name, func(t *testing.T) {
+			b, err := yamagiconf.SetValue[SetValueConfig](
+				[]byte(setValueTestSrc), td.path, td.value,
+			)
+			require.NoError(t, err)
+			require.Equal(t, td.expect, string(b))
+		})
+	}
+}
+
+func TestSetValueKeyOrder(t *testing.T) {
+	type Inner struct {
+		A string `yaml:"a"`
+		B string `yaml:"b" default:"b"`
+		C string `yaml:"c"`
+	}
+	type TestConfig struct {
+		Name  string  `yaml:"name"`
+		Level int32   `yaml:"level" default:"1"`
+		Inner Inner   `yaml:"inner"`
+		Ptr   *Inner  `yaml:"ptr"`
+		Items []Inner `yaml:"items"`
+	}
+	src := `name: svc
+inner:
+  a: x
+  # Comment of c.
+  c: z
+ptr:
+  a: x
+  c: z
+items:
+  - a: x
+    c: z
+`
+	opt := yamagiconf.WithStrictKeyOrder()
+	for _, td := range []struct {
+		path   string
+		expect string
+	}{
+		{"level", `name: svc
+level: 2
+inner:
+  a: x
+  # Comment of c.
+  c: z
+ptr:
+  a: x
+  c: z
+items:
+  - a: x
+    c: z
+`},
+		{"inner.b", `name: svc
+inner:
+  a: x
+  b: "2"
+  # Comment of c.
+  c: z
+ptr:
+  a: x
+  c: z
+items:
+  - a: x
+    c: z
+`},
+		{"ptr.b", `name: svc
+inner:
+  a: x
+  # Comment of c.
+  c: z
+ptr:
+  a: x
+  b: "2"
+  c: z
+items:
+  - a: x
+    c: z
+`},
+		{"items[0].b", `name: svc
+inner:
+  a: x
+  # Comment of c.
+  c: z
+ptr:
+  a: x
+  c: z
+items:
+  - a: x
+    b: "2"
+    c: z
+`},
+	} {
+		t.Run(td.path, func(t *testing.T) {
+			var value any = "2"
+			if td.path == "level" {
+				value = int32(2)
+			}
+			b, err := yamagiconf.SetValue[TestConfig]([]byte(src), td.path, value, opt)
+			require.NoError(t, err)
+			require.Equal(t, td.expect, string(b))
+		})
+	}
+}
+
+func TestSetValueKeepsLayout(t *testing.T) {
+	src := `# Service configuration.
+
+server:
+    host: "local\"host" # The host name.
+
+    # The port to listen on.
+    port: 8080
+servers:
+    -   host: 'a''s.local'
+        port: 81
+    - {host: b.local, port: 82}
+labels: {env: prod, team: "core"}   # Flow mapping.
+proxy: null
+`
+	for _, td := range []struct {
+		path  string
+		value any
+		line  int
+		want  string
+	}{
+		{"server.host", "example.com", 3, `    host: example.com # The host name.`},
+		{"server.port", uint16(9090), 6, `    port: 9090`},
+		{"servers[0].host", "c.local", 8, `    -   host: c.local`},
+		{"servers[1].port", uint16(83), 10, `    - {host: b.local, port: 83}`},
+		{"servers[1].host", "d,e", 10, `    - {host: "d,e", port: 82}`},
+		{"labels[team]", "yes", 11, `labels: {env: prod, team: "yes"}   # Flow mapping.`},
+		{"proxy", "~", 12, `proxy: "~"`},
+	} {
+		t.Run(td.path, func(t *testing.T) {
+			b, err := yamagiconf.SetValue[SetValueConfig]([]byte(src), td.path, td.value)
+			require.NoError(t, err)
+			expect := strings.Split(src, "\n")
+			expect[td.line] = td.want
+			require.Equal(t, expect, strings.Split(string(b), "\n"))
+		})
+	}
+}
+
+func TestSetValueNull(t *testing.T) {
+	src := "server:\n  host: x\n  port: 1\nservers: []\nlabels: {}\nproxy: p\n"
+	b, err := yamagiconf.SetValue[SetValueConfig]([]byte(src), "labels", nil)
+	require.NoError(t, err)
+	require.Equal(t, "server:\n  host: x\n  port: 1\nservers: []\n"+
+		"labels: null\nproxy: p\n", string(b))
+
+	l, err := yamagiconf.NewLoader[SetValueConfig]()
+	require.NoError(t, err)
+	b, err = l.SetValue(b, "proxy", nil)
+	require.NoError(t, err)
+	require.Equal(t, "server:\n  host: x\n  port: 1\nservers: []\n"+
+		"labels: null\nproxy: null\n", string(b))
+}
+
+func TestSetValueErr(t *testing.T) {
+	src := []byte(setValueTestSrc)
+	for _, td := range []struct {
+		name  string
+		path  string
+		value any
+		check func(t *testing.T, err error)
+	}{
+		{"validation", "server.port", uint16(0), func(t *testing.T, err error) {
+			require.ErrorIs(t, err, yamagiconf.ErrValidationTag)
+		}},
+		{"null_on_non_pointer", "server.host", nil, func(t *testing.T, err error) {
+			require.ErrorIs(t, err, yamagiconf.ErrYAMLNullOnNonPointer)
+		}},
+		{"type_mismatch", "server.port", "http", func(t *testing.T, err error) {
+			require.ErrorIs(t, err, yamagiconf.ErrYAMLMalformed)
+		}},
+		{"unknown_key", "server.nope", "x", func(t *testing.T, err error) {
+			require.ErrorIs(t, err, yamagiconf.ErrYAMLMalformed)
+		}},
+		{"missing_parent", "nope.host", "x", func(t *testing.T, err error) {
+			require.ErrorIs(t, err, yamagiconf.ErrPathNotFound)
+			require.Equal(t, `path not found: nope.host: missing key "nope"`,
+				err.Error())
+		}},
+		{"index_out_of_range", "servers[1].host", "x", func(t *testing.T, err error) {
+			require.ErrorIs(t, err, yamagiconf.ErrPathNotFound)
+		}},
+		{"scalar_parent", "server.host.x", "x", func(t *testing.T, err error) {
+			require.ErrorIs(t, err, yamagiconf.ErrPathNotFound)
+		}},
+		{"syntax", "servers[0", "x", func(t *testing.T, err error) {
+			require.ErrorIs(t, err, yamagiconf.ErrPathNotFound)
+		}},
+		{"empty", "", "x", func(t *testing.T, err error) {
+			require.ErrorIs(t, err, yamagiconf.ErrPathNotFound)
+		}},
+	} {
+		t.Run(td.name, func(t *testing.T) {
+			_, err := yamagiconf.SetValue[SetValueConfig](src, td.path, td.value)
+			td.check(t, err)
+		})
+	}
+
+	_, err := yamagiconf.SetValue[SetValueConfig](
+		[]byte("server: &s\n  host: x\n  port: 1\nservers: [*s]\n"+
+			"labels: {}\nproxy: null\n"), "servers[0].host", "y")
+	require.ErrorIs(t, err, yamagiconf.ErrPathNotFound)
+}
@@ -0,0 +1,120 @@
+package yamagiconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadStream reads and validates a multi-document YAML stream decoding
+// every document (separated by `---`) into a separate value of type T.
+// Every document is subject to the same rules as a file loaded with LoadFile,
+// anchors are scoped per document and aliases must not refer to anchors
+// defined in other documents of the stream.
+// An empty document at the end of the stream (like after a trailing `---`)
+// is ignored, empty documents elsewhere are reported as ErrYAMLEmptyDocument.
+// Errors are prefixed with the zero-based index of the document.
+func LoadStream[T any, S string | []byte](yamlSource S, opts ...Option) ([]T, error) {
+	if len(yamlSource) == 0 {
+		return nil, ErrYAMLEmptyFile
+	}
+
+	o := newOptions(opts)
+	if err := validateType[T](o); err != nil {
+		return nil, err
+	}
+
+	return loadStream[T](o, yamlSource)
+}
+
+// loadStream decodes and validates every document of yamlSource.
+// Assumes that T has already been validated using ValidateType.
+func loadStream[T any, S string | []byte](o *options, yamlSource S) ([]T, error) {
+	if len(yamlSource) == 0 {
+		return nil, ErrYAMLEmptyFile
+	}
+
+	dec := newDecoderYAML(yamlSource)
+	var configs []T
+	var empty *yaml.Node // The last document if it was empty.
+	for index := 0; ; index++ {
+		var rootNode yaml.Node
+		if err := dec.Decode(&rootNode); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("document %d: %w: %w",
+				index, ErrYAMLMalformed, err)
+		}
+		if empty != nil {
+			return nil, fmt.Errorf("document %d: at %d:%d: %w",
+				index-1, empty.Line, empty.Column, ErrYAMLEmptyDocument)
+		}
+		if isEmptyDocument(&rootNode) {
+			empty = &rootNode
+			continue
+		}
+
+		var config T
+		defaults := map[*yaml.Node]struct{}{}
+		applyDefaults(reflect.TypeOf(config), rootNode.Content[0], defaults)
+		if err := decodeDocument(o, &rootNode, &config); err != nil {
+			return nil, fmt.Errorf("document %d: %w: %w",
+				index, ErrYAMLMalformed, err)
+		}
+
+		if err := validateAliasesLocal(&rootNode); err != nil {
+			return nil, fmt.Errorf("document %d: %w", index, err)
+		}
+		if err := validateLoaded(
+			context.Background(), o, &rootNode, defaults, &config,
+		); err != nil {
+			return nil, fmt.Errorf("document %d: %w", index, err)
+		}
+		configs = append(configs, config)
+	}
+	if len(configs) < 1 {
+		return nil, ErrYAMLEmptyFile
+	}
+	return configs, nil
+}
+
+// isEmptyDocument reports whether document contains nothing but comments.
+func isEmptyDocument(document *yaml.Node) bool {
+	n := document.Content[0]
+	return n.Kind == yaml.ScalarNode && n.Tag == "!!null" && n.Value == "" &&
+		n.Style == 0
+}
+
+// validateAliasesLocal returns an error if any alias within document refers
+// to an anchor that isn't defined within the same document.
+// yaml.v3 resolves aliases across the whole stream, which would otherwise
+// allow documents to depend on each other.
+func validateAliasesLocal(document *yaml.Node) error {
+	anchors := map[*yaml.Node]struct{}{}
+	var aliases []*yaml.Node
+	var traverse func(n *yaml.Node)
+	traverse = func(n *yaml.Node) {
+		if n.Anchor != "" {
+			anchors[n] = struct{}{}
+		}
+		if n.Kind == yaml.AliasNode {
+			aliases = append(aliases, n)
+		}
+		for _, c := range n.Content {
+			traverse(c)
+		}
+	}
+	traverse(document)
+	for _, a := range aliases {
+		if _, ok := anchors[a.Alias]; !ok {
+			return fmt.Errorf("at %d:%d: alias %q: %w",
+				a.Line, a.Column, a.Value, ErrYAMLAnchorForeign)
+		}
+	}
+	return nil
+}
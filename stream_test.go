@@ -0,0 +1,162 @@
+package yamagiconf_test
+
+import (
+	"testing"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadStream(t *testing.T) {
+	type Item struct {
+		Name string `yaml:"name" validate:"required"`
+		Port uint16 `yaml:"port"`
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		c, err := yamagiconf.LoadStream[Item](`# fixtures
+name: first
+port: 8080
+---
+name: second
+port: 8081
+---
+name: third
+port: 8082
+`)
+		require.NoError(t, err)
+		require.Equal(t, []Item{
+			{Name: "first", Port: 8080},
+			{Name: "second", Port: 8081},
+			{Name: "third", Port: 8082},
+		}, c)
+	})
+
+	t.Run("single_document", func(t *testing.T) {
+		c, err := yamagiconf.LoadStream[Item]([]byte("name: only\nport: 1"))
+		require.NoError(t, err)
+		require.Equal(t, []Item{{Name: "only", Port: 1}}, c)
+	})
+
+	t.Run("anchors_scoped_per_document", func(t *testing.T) {
+		type TestConfig struct {
+			A string `yaml:"a"`
+			B string `yaml:"b"`
+		}
+		c, err := yamagiconf.LoadStream[TestConfig](`
+a: &x first
+b: *x
+---
+a: &x second
+b: *x
+`)
+		require.NoError(t, err)
+		require.Equal(t, []TestConfig{
+			{A: "first", B: "first"},
+			{A: "second", B: "second"},
+		}, c)
+	})
+
+	t.Run("trailing_separator", func(t *testing.T) {
+		c, err := yamagiconf.LoadStream[Item]("name: first\nport: 1\n---\n# end\n")
+		require.NoError(t, err)
+		require.Equal(t, []Item{{Name: "first", Port: 1}}, c)
+	})
+
+	t.Run("err_empty_document", func(t *testing.T) {
+		_, err := yamagiconf.LoadStream[Item]("name: first\nport: 1\n---\n" +
+			"---\nname: second\nport: 2\n")
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLEmptyDocument)
+		require.Equal(t, `document 1: at 3:1: `+
+			yamagiconf.ErrYAMLEmptyDocument.Error(), err.Error())
+	})
+
+	t.Run("err_only_separator", func(t *testing.T) {
+		_, err := yamagiconf.LoadStream[Item]("---\n")
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLEmptyFile)
+	})
+
+	t.Run("err_empty", func(t *testing.T) {
+		_, err := yamagiconf.LoadStream[Item]("")
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLEmptyFile)
+	})
+
+	t.Run("err_alias_to_other_document", func(t *testing.T) {
+		type TestConfig struct {
+			A string `yaml:"a"`
+			B string `yaml:"b"`
+		}
+		_, err := yamagiconf.LoadStream[TestConfig](`a: &x first
+b: *x
+---
+a: second
+b: *x
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLAnchorForeign)
+		require.Equal(t, `document 1: at 5:4: alias "x": `+
+			yamagiconf.ErrYAMLAnchorForeign.Error(), err.Error())
+	})
+
+	t.Run("err_unused_anchor_in_second_document", func(t *testing.T) {
+		_, err := yamagiconf.LoadStream[Item](`name: first
+port: 8080
+---
+name: &n second
+port: 8081
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLAnchorUnused)
+		require.Equal(t, `document 1: at 4:7: anchor "n": `+
+			yamagiconf.ErrYAMLAnchorUnused.Error(), err.Error())
+	})
+
+	t.Run("err_validation_in_third_document", func(t *testing.T) {
+		_, err := yamagiconf.LoadStream[Item](`name: first
+port: 8080
+---
+name: second
+port: 8081
+---
+name: ''
+port: 8082
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrValidationTag)
+		require.Equal(t, `document 2: at 7:7: "name" `+
+			`violates validation rule: "required"`, err.Error())
+	})
+
+	t.Run("err_missing_field", func(t *testing.T) {
+		_, err := yamagiconf.LoadStream[Item]("name: first\nport: 1\n---\nname: x\n")
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLMissingConfig)
+		require.Equal(t, `document 1: at Item.Port (as "port"): `+
+			yamagiconf.ErrYAMLMissingConfig.Error(), err.Error())
+	})
+
+	t.Run("err_unknown_field", func(t *testing.T) {
+		_, err := yamagiconf.LoadStream[Item](`name: first
+port: 8080
+---
+name: second
+port: 8081
+unknown: field
+`)
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLMalformed)
+	})
+
+	t.Run("err_malformed_document", func(t *testing.T) {
+		_, err := yamagiconf.LoadStream[Item]("name: first\nport: 1\n---\n:\n")
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLMalformed)
+		require.Equal(t, `document 1: malformed YAML: `+
+			`yaml: line 3: did not find expected key`, err.Error())
+	})
+}
+
+func TestLoadAliasBeforeAnchorInStructOrder(t *testing.T) {
+	type TestConfig struct {
+		A string `yaml:"a"`
+		B string `yaml:"b"`
+	}
+	c, err := LoadSrc[TestConfig]("b: &x foo\na: *x\n")
+	require.NoError(t, err)
+	require.Equal(t, TestConfig{A: "foo", B: "foo"}, *c)
+}
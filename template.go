@@ -0,0 +1,173 @@
+package yamagiconf
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Template returns a YAML document for T that contains every field with
+// a placeholder value to serve as a starting point for new configuration files.
+// Every key is preceded by a comment containing the documentation of
+// the field (see RegisterFieldDocs) and notes on its env var, `validate` rules,
+// default value and whether it's a secret.
+//
+// Placeholders are the zero values of their types, `null` for pointers to
+// non-struct types and for yaml.Unmarshaler implementations and
+// the default value for fields with a `default` struct tag.
+// Pointers to structs are expanded, slices and maps of structs contain
+// a single example item, other slices and maps are empty.
+// Anchors and aliases are never used.
+// The template complies with all rules of LoadFile and passes Load once
+// the placeholders are replaced by values satisfying the validation rules.
+//
+// T is validated with opts as by ValidateType. The template passes Load with
+// the same opts since keys follow the order of the struct fields and the only
+// map key used is `key`.
+// Template panics if T isn't a valid configuration type (see ValidateType).
+func Template[T any](opts ...Option) []byte {
+	if err := validateType[T](newOptions(opts)); err != nil {
+		panic(err)
+	}
+	var t T
+	node := templateNode(reflect.TypeOf(t))
+	var b bytes.Buffer
+	if err := encodeYAML(&b, node); err != nil {
+		panic(fmt.Errorf("encoding template: %w", err))
+	}
+	return b.Bytes()
+}
+
+// templateNode returns the placeholder node of type tp.
+// Assumes that tp has already been validated using ValidateType.
+func templateNode(tp reflect.Type) *yaml.Node {
+	if t, ok := secretValueType(tp); ok {
+		tp = t
+	}
+	switch {
+	case tp.Kind() == reflect.Pointer:
+		if isPlainStruct(tp.Elem()) {
+			return templateNode(tp.Elem())
+		}
+		return newNullNode()
+	case tp == typeTimeDuration:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "0s"}
+	case implementsInterface[encoding.TextUnmarshaler](tp):
+		return newStringNode("")
+	case implementsInterface[yaml.Unmarshaler](tp):
+		return newNullNode()
+	}
+
+	switch tp.Kind() {
+	case reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		templateFields(tp, node)
+		return node
+	case reflect.Array:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for range tp.Len() {
+			node.Content = append(node.Content, templateNode(tp.Elem()))
+		}
+		if len(node.Content) < 1 || !isPlainStruct(tp.Elem()) {
+			node.Style = yaml.FlowStyle
+		}
+		return node
+	case reflect.Slice:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if isPlainStruct(tp.Elem()) {
+			node.Content = append(node.Content, templateNode(tp.Elem()))
+		} else {
+			node.Style = yaml.FlowStyle
+		}
+		return node
+	case reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		if isPlainStruct(tp.Elem()) {
+			key := templateNode(tp.Key())
+			if key.Value == "" {
+				key = newStringNode("key")
+			}
+			node.Content = append(node.Content, key, templateNode(tp.Elem()))
+		} else {
+			node.Style = yaml.FlowStyle
+		}
+		return node
+	}
+
+	// Primitives.
+	node := new(yaml.Node)
+	if err := node.Encode(reflect.Zero(tp).Interface()); err != nil {
+		panic(fmt.Errorf("encoding placeholder of %s: %w", tp.String(), err))
+	}
+	return node
+}
+
+// templateFields adds the fields of struct type tp including the fields
+// of embedded inline structs to mapping node.
+func templateFields(tp reflect.Type, node *yaml.Node) {
+	for _, f := range getStructInfo(tp).Fields {
+		switch {
+		case f.YAMLTag == "-":
+			continue
+		case f.Anonymous:
+			t := f.Type
+			if t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			if t.Kind() != reflect.Map {
+				templateFields(t, node)
+			}
+			continue
+		}
+		var value *yaml.Node
+		switch {
+		case f.Default != nil:
+			value = copyNodeAt(f.Default, 0, 0)
+		case isEnvOnlySecretField(f.StructField):
+			value = newStringNode("")
+			if isNilableKind(f.Type.Kind()) {
+				value = newNullNode()
+			}
+		default:
+			value = templateNode(f.Type)
+		}
+		key := newStringNode(f.YAMLTag)
+		key.HeadComment = templateComment(tp, f)
+		node.Content = append(node.Content, key, value)
+	}
+}
+
+// templateComment returns the comment preceding the key of field f
+// of struct type tp.
+func templateComment(tp reflect.Type, f structField) string {
+	var lines []string
+	if doc := fieldDoc(tp, f.StructField); doc != "" {
+		lines = append(lines, strings.Split(doc, "\n")...)
+	}
+	if env := f.Tag.Get("env"); env != "" {
+		lines = append(lines, "env: "+env)
+	}
+	if v := f.Tag.Get("validate"); v != "" {
+		lines = append(lines, "validate: "+v)
+	}
+	if d, ok := f.Tag.Lookup("default"); ok {
+		lines = append(lines, "default: "+d)
+	}
+	if isEnvOnlySecretField(f.StructField) {
+		lines = append(lines, "secret: set via env var only")
+	} else if isSecretField(f.StructField) {
+		lines = append(lines, "secret")
+	}
+	for i, l := range lines {
+		lines[i] = strings.TrimRight("# "+l, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func newNullNode() *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+}
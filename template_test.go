@@ -0,0 +1,102 @@
+package yamagiconf_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romshark/yamagiconf"
+
+	"github.com/stretchr/testify/require"
+)
+
+type TemplateConfig struct {
+	Name             string               `yaml:"name" doc:"Name of the service." validate:"required"`
+	Port             uint16               `yaml:"port" env:"TEMPLATE_TEST_PORT" validate:"min=1"`
+	Debug            bool                 `yaml:"debug"`
+	Ratio            float64              `yaml:"ratio"`
+	Timeout          time.Duration        `yaml:"timeout" default:"30s"`
+	Proxy            *string              `yaml:"proxy"`
+	Token            string               `yaml:"token" env:"TEMPLATE_TEST_TOKEN" secret:"env-only"`
+	Password         string               `yaml:"password" secret:"true"`
+	Tags             []string             `yaml:"tags"`
+	Pair             [2]int8              `yaml:"pair"`
+	Labels           map[string]string    `yaml:"labels"`
+	Servers          []TemplateServer     `yaml:"servers" doc:"Backend servers.\nOrdered by priority."`
+	Zones            map[string]TemplateZ `yaml:"zones"`
+	TLS              *TemplateTLS         `yaml:"tls"`
+	Text             ValidatedString      `yaml:"text"`
+	Ignored          string               `yaml:"-"`
+	TemplateEmbedded `yaml:",inline"`
+}
+
+type TemplateServer struct {
+	Host string `yaml:"host" doc:"Host name."`
+}
+
+type TemplateZ struct {
+	Weight uint8 `yaml:"weight"`
+}
+
+type TemplateTLS struct {
+	Cert string `yaml:"cert"`
+}
+
+type TemplateEmbedded struct {
+	Region string `yaml:"region"`
+}
+
+func TestTemplate(t *testing.T) {
+	tmpl := string(yamagiconf.Template[TemplateConfig]())
+	require.Equal(t, `# Name of the service.
+# validate: required
+name: ""
+# env: TEMPLATE_TEST_PORT
+# validate: min=1
+port: 0
+debug: false
+ratio: 0
+# default: 30s
+timeout: 30s
+proxy: null
+# env: TEMPLATE_TEST_TOKEN
+# secret: set via env var only
+token: ""
+# secret
+password: ""
+tags: []
+pair: [0, 0]
+labels: {}
+# Backend servers.
+# Ordered by priority.
+servers:
+  - # Host name.
+    host: ""
+zones:
+  key:
+    weight: 0
+tls:
+  cert: ""
+text: ""
+region: ""
+`, tmpl)
+	require.NotContains(t, tmpl, "&")
+	require.NotContains(t, tmpl, "*")
+
+	// The template is loadable once placeholders are filled.
+	filled := strings.Replace(tmpl, `name: ""`, `name: svc`, 1)
+	filled = strings.Replace(filled, `port: 0`, `port: 8080`, 1)
+	filled = strings.Replace(filled, `text: ""`, `text: valid`, 1)
+	c, err := LoadSrc[TemplateConfig](filled)
+	require.NoError(t, err)
+	require.Equal(t, "svc", c.Name)
+	require.Equal(t, 30*time.Second, c.Timeout)
+	require.Len(t, c.Servers, 1)
+}
+
+func TestTemplatePanics(t *testing.T) {
+	type TestConfig struct {
+		Port int `yaml:"port"`
+	}
+	require.Panics(t, func() { yamagiconf.Template[TestConfig]() })
+}
@@ -7,6 +7,7 @@ package yamagiconf
 
 import (
 	"bytes"
+	"context"
 	"encoding"
 	"errors"
 	"fmt"
@@ -14,9 +15,11 @@ import (
 	"os"
 	"reflect"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -30,10 +33,21 @@ import (
 var (
 	ErrConfigNil     = errors.New("cannot load into nil config")
 	ErrValidation    = errors.New("validation")
+	ErrNormalization = errors.New("normalization")
 	ErrValidationTag = errors.New("violates validation rule")
+	ErrRefUndefined  = errors.New("reference to undefined name")
+	ErrDocsFormat    = errors.New("unsupported docs format")
+	ErrPathNotFound  = errors.New("path not found")
+
+	ErrTranslatorWithoutValidator = errors.New("WithTranslator requires " +
+		"WithValidator with the translations registered")
+
+	ErrFormatAliasOrder = errors.New("reordering keys would move an alias " +
+		"before its anchor")
 
 	ErrYAMLMultidoc        = errors.New("multi-document YAML files are not supported")
 	ErrYAMLEmptyFile       = errors.New("empty file")
+	ErrYAMLEmptyDocument   = errors.New("empty document")
 	ErrYAMLMalformed       = errors.New("malformed YAML")
 	ErrYAMLInlineNonAnon   = errors.New("inline yaml on non-embedded field")
 	ErrYAMLInlineOpt       = errors.New("use `yaml:\",inline\"` for embedded fields")
@@ -52,7 +66,16 @@ var (
 		"any other variants of null are not supported")
 	ErrYAMLNonStrOnTextUnmarsh = errors.New("value must be a string because the " +
 		"target type implements encoding.TextUnmarshaler")
-	ErrYAMLMergeKey = errors.New("avoid using YAML merge keys")
+	ErrYAMLMergeKey      = errors.New("avoid using YAML merge keys")
+	ErrYAMLAnchorUsed    = errors.New("avoid using YAML anchors and aliases")
+	ErrYAMLAnchorForeign = errors.New("yaml aliases must refer to anchors " +
+		"defined in the same document")
+	ErrYAMLKeyConvention = errors.New("map key doesn't follow " +
+		"the naming convention")
+	ErrYAMLKeyOrder = errors.New("keys must appear in the order " +
+		"of the struct fields")
+	ErrYAMLPlaintextSecret = errors.New("env-only secrets must not be defined " +
+		"in the YAML file, use null or an empty value and set the env var instead")
 
 	// ErrYAMLEmptyArrayItem applies to both Go arrays and slices even though
 	// an empty item would be parsed correctly as zero-value in case of Go arrays
@@ -73,8 +96,14 @@ var (
 	ErrTypeInvalidEnvTag    = fmt.Errorf("invalid env struct tag: "+
 		"must match the POSIX env var regexp: %s", regexEnvVarPOSIXPattern)
 	ErrTypeEnvVarOnUnsupportedType = errors.New("env var on unsupported type")
+	ErrTypeInvalidValidateTag      = errors.New("invalid validate struct tag")
+	ErrTypeInvalidRefTag           = errors.New("invalid ref struct tag")
+	ErrTypeInvalidDefaultTag       = errors.New("invalid default struct tag")
+	ErrTypeInvalidSecretTag        = errors.New("invalid secret struct tag")
 	ErrTypeUnsupported             = errors.New("unsupported type")
 	ErrTypeUnsupportedPtrType      = errors.New("unsupported pointer type")
+	ErrTypeYAMLTagConvention       = errors.New("yaml struct tag doesn't " +
+		"follow the naming convention")
 
 	ErrEnvInvalidVar = errors.New("invalid env var")
 )
@@ -84,32 +113,77 @@ var (
 //   - ValidateType returns an error for T.
 //   - the yaml file is empty or not found.
 //   - the yaml file doesn't contain a field specified by T.
-//   - the yaml file is missing a field specified by T.
+//   - the yaml file is missing a field specified by T
+//     (except for fields with a `default` struct tag).
 //   - the yaml file contains values that don't pass validation.
-//   - the yaml file contains boolean literals other than `true` and `false`.
-//   - the yaml file contains null values other than `null` (`~`, etc.).
-//   - the yaml file assigns `null` to a non-pointer Go type.
-//   - the yaml file contains any YAML tags (https://yaml.org/spec/1.2.2/#3212-tags).
-//   - the yaml file contains any redeclared anchors.
-//   - the yaml file contains any unused anchors.
-//   - the yaml file contains any anchors with implicit null value (no value).
+//   - the yaml file contains boolean literals other than `true` and `false`
+//     (unless Rules.AllowBoolVariants).
+//   - the yaml file contains null values other than `null` (`~`, etc.)
+//     (unless Rules.AllowNullVariants). Quoted scalars like `"~"` are strings.
+//   - the yaml file assigns `null` to a non-pointer Go type
+//     (unless Rules.AllowNullOnNonPointer).
+//   - the yaml file contains any YAML tags (https://yaml.org/spec/1.2.2/#3212-tags)
+//     (unless Rules.AllowYAMLTags).
+//   - the yaml file contains any merge keys (unless Rules.AllowMergeKeys).
+//   - the yaml file contains any anchors or aliases (only with
+//     Rules.ForbidAnchors).
+//   - the yaml file contains any redeclared anchors
+//     (unless Rules.AllowAnchorRedefinition).
+//   - the yaml file contains any unused anchors
+//     (unless Rules.AllowUnusedAnchors).
+//   - the yaml file contains any anchors with implicit null value (no value)
+//     (unless Rules.AllowAnchorNoValue).
+//   - the yaml file contains any empty array items
+//     (unless Rules.AllowEmptyArrayItems).
 //   - the yaml file assigns non-string values to Go types implementing the
 //     encoding.TextUnmarshaler interface.
-func LoadFile[T any](yamlFilePath string, config *T) error {
+//   - the yaml file assigns a value other than null or an empty value
+//     to a field tagged `secret:"env-only"`.
+//   - the yaml file contains keys that aren't in the order of the struct
+//     fields (only with Rules.StrictKeyOrder, see WithStrictKeyOrder).
+//   - the yaml file contains keys of maps with string keys that don't follow
+//     the naming convention (only with Rules.MapKeyConvention,
+//     see WithMapKeyConvention).
+//   - any implementation of Normalizer, Validator or ValidatorContext
+//     within T returns an error.
+//
+// The rules can be changed with option WithRules.
+func LoadFile[T any](yamlFilePath string, config *T, opts ...Option) error {
+	return LoadFileContext(context.Background(), yamlFilePath, config, opts...)
+}
+
+// LoadFileContext behaves like LoadFile but passes ctx to all
+// implementations of ValidatorContext and stops when ctx is canceled.
+func LoadFileContext[T any](
+	ctx context.Context, yamlFilePath string, config *T, opts ...Option,
+) error {
 	if config == nil {
 		return ErrConfigNil
 	}
 
+	o := newOptions(opts)
+	if err := validateType[T](o); err != nil {
+		return err
+	}
+
 	yamlSrcBytes, err := os.ReadFile(yamlFilePath)
 	if err != nil {
 		return fmt.Errorf("reading file %q: %w", yamlFilePath, err)
 	}
-	return Load(yamlSrcBytes, config)
+	return load(ctx, o, yamlSrcBytes, config, yamlFilePath, nil)
 }
 
 // Load reads and validates the configuration of type T from yamlSource.
 // Load behaves similar to LoadFile.
-func Load[T any, S string | []byte](yamlSource S, config *T) error {
+func Load[T any, S string | []byte](yamlSource S, config *T, opts ...Option) error {
+	return LoadContext(context.Background(), yamlSource, config, opts...)
+}
+
+// LoadContext behaves like Load but passes ctx to all
+// implementations of ValidatorContext and stops when ctx is canceled.
+func LoadContext[T any, S string | []byte](
+	ctx context.Context, yamlSource S, config *T, opts ...Option,
+) error {
 	if config == nil {
 		return ErrConfigNil
 	}
@@ -117,48 +191,90 @@ func Load[T any, S string | []byte](yamlSource S, config *T) error {
 		return ErrYAMLEmptyFile
 	}
 
-	if err := ValidateType[T](); err != nil {
+	o := newOptions(opts)
+	if err := validateType[T](o); err != nil {
+		return err
+	}
+
+	return load(ctx, o, yamlSource, config, "", nil)
+}
+
+// load decodes yamlSource into config and performs all checks.
+// If prov != nil then it's filled with the sources of all values
+// located in file.
+// Assumes that T has already been validated using ValidateType.
+func load[T any, S string | []byte](
+	ctx context.Context, o *options, yamlSource S, config *T,
+	file string, prov Provenance,
+) error {
+	if config == nil {
+		return ErrConfigNil
+	}
+	if len(yamlSource) == 0 {
+		return ErrYAMLEmptyFile
+	}
+	if err := ctx.Err(); err != nil {
 		return err
 	}
 
+	var rootNode yaml.Node
 	dec := newDecoderYAML(yamlSource)
-	dec.KnownFields(true)
-	err := dec.Decode(config)
-	if err != nil {
+	if err := dec.Decode(&rootNode); err != nil {
+		return fmt.Errorf("%w: %w", ErrYAMLMalformed, err)
+	}
+	defaults := map[*yaml.Node]struct{}{}
+	configType := reflect.TypeOf(config).Elem()
+	if len(rootNode.Content) > 0 {
+		applyDefaults(configType, rootNode.Content[0], defaults)
+	}
+	if err := decodeDocument(o, &rootNode, config); err != nil {
 		return fmt.Errorf("%w: %w", ErrYAMLMalformed, err)
 	}
 
-	var rootNode yaml.Node
-	{
-		dec := newDecoderYAML(yamlSource)
-		if err := dec.Decode(&rootNode); err != nil {
-			return fmt.Errorf("decoding yaml structure: %w", err)
-		}
+	// Check if multi-doc
+	var n yaml.Node
+	if err := dec.Decode(&n); err == nil {
+		return fmt.Errorf("at %d:%d: %w", n.Line, n.Column, ErrYAMLMultidoc)
+	} else if !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: %w", ErrYAMLMultidoc, err)
+	}
 
-		// Check if multi-doc
-		var n yaml.Node
-		if err = dec.Decode(&n); err == nil {
-			return fmt.Errorf("at %d:%d: %w", n.Line, n.Column, ErrYAMLMultidoc)
-		} else if !errors.Is(err, io.EOF) {
-			return fmt.Errorf("%w: %w", ErrYAMLMultidoc, err)
-		}
+	if err := validateLoaded(ctx, o, &rootNode, defaults, config); err != nil {
+		return err
+	}
+	if prov != nil {
+		prov.recordFields(file, "", configType, rootNode.Content[0], defaults)
 	}
+	return nil
+}
 
+// validateLoaded performs all checks on config after it was decoded from the
+// YAML document rootNode and applies env var overrides.
+// defaults are the value nodes added to rootNode by applyDefaults.
+// Assumes that T has already been validated using ValidateType.
+func validateLoaded[T any](
+	ctx context.Context, o *options, rootNode *yaml.Node,
+	defaults map[*yaml.Node]struct{}, config *T,
+) error {
 	configType := reflect.TypeOf(config).Elem()
 
 	configTypeName := getConfigTypeName(configType)
 
-	anchors := make(map[string]*anchor)
-	err = validateYAMLValues(
-		anchors, "", configTypeName, configType, rootNode.Content[0],
+	v := &yamlValidation{
+		opts:     o,
+		anchors:  make(map[string]*anchor),
+		defaults: defaults,
+	}
+	err := validateYAMLValues(
+		v, "", configTypeName, configType, rootNode.Content[0],
 	)
 	if err != nil {
 		return err
 	}
 
 	// Check for unused anchors
-	for _, anchor := range anchors {
-		if !anchor.IsUsed {
+	for _, anchor := range v.anchors {
+		if !anchor.IsUsed && !o.rules.AllowUnusedAnchors {
 			return fmt.Errorf("at %d:%d: anchor %q: %w",
 				anchor.Line, anchor.Column, anchor.Anchor, ErrYAMLAnchorUnused)
 		}
@@ -169,61 +285,196 @@ func Load[T any, S string | []byte](yamlSource S, config *T) error {
 		return err
 	}
 
-	err = invokeValidateRecursively(
+	err = invokeNormalizeRecursively(
 		configTypeName, reflect.ValueOf(config), rootNode.Content[0],
 	)
 	if err != nil {
 		return err
 	}
 
-	err = validator.New(
-		validator.WithRequiredStructEnabled(),
-	).Struct(config)
+	err = validateRefs(
+		configTypeName, reflect.ValueOf(config).Elem(), rootNode.Content[0],
+	)
 	if err != nil {
-		if errs, ok := err.(validator.ValidationErrors); ok {
-			err := errs[0]
-			line, column, yamlTag := mustFindLocationByValidatorNamespace[T](
-				err.StructNamespace(), &rootNode,
-			)
-			if yamlTag == "-" {
-				// TODO: report env var name if any.
+		return err
+	}
 
-				// Ignored field, use Go field name instead of tag.
-				return fmt.Errorf("at %s: %w: %q",
-					err.StructNamespace(), ErrValidationTag, err.Tag())
-			}
-			return fmt.Errorf("at %d:%d: %q %w: %q",
-				line, column, yamlTag, ErrValidationTag, err.Tag())
+	err = invokeValidateRecursively(
+		ctx, config, configTypeName, reflect.ValueOf(config), rootNode.Content[0],
+	)
+	if err != nil {
+		return err
+	}
+
+	err = o.validate.Struct(config)
+	if err != nil {
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			return validationTagErrors[T](o, errs, rootNode)
 		}
 		return err
 	}
 	return nil
 }
 
+// validationTagErrors returns an error reporting every violation in errs.
+// Violations are reported by line:column if rootNode != nil, otherwise
+// by the Go path.
+func validationTagErrors[T any](
+	o *options, errs validator.ValidationErrors, rootNode *yaml.Node,
+) error {
+	all := make([]error, len(errs))
+	for i, fieldErr := range errs {
+		all[i] = validationTagError[T](o, fieldErr, rootNode)
+	}
+	return errors.Join(all...)
+}
+
+func validationTagError[T any](
+	o *options, fieldErr validator.FieldError, rootNode *yaml.Node,
+) error {
+	rule := fieldErr.Tag()
+	if p := fieldErr.Param(); p != "" {
+		rule += "=" + p
+	}
+	var details string
+	// The value is omitted for rules like "required" where it's obvious.
+	if v := reflect.ValueOf(fieldErr.Value()); fieldErr.Param() != "" ||
+		(v.IsValid() && !v.IsZero()) {
+		if isSecretNamespace[T](fieldErr.StructNamespace()) {
+			details = ", got " + Redacted
+		} else {
+			details = ", got " + formatValue(fieldErr.Value())
+		}
+	}
+	if o.translator != nil {
+		details += ": " + fieldErr.Translate(o.translator)
+	}
+
+	if rootNode != nil {
+		line, column, yamlTag := mustFindLocationByValidatorNamespace[T](
+			fieldErr.StructNamespace(), rootNode,
+		)
+		if yamlTag != "-" {
+			return fmt.Errorf("at %d:%d: %q %w: %q%s",
+				line, column, yamlTag, ErrValidationTag, rule, details)
+		}
+		// TODO: report env var name if any.
+	}
+	// Ignored field or no YAML, use Go path instead of tag.
+	return fmt.Errorf("at %s: %w: %q%s",
+		fieldErr.StructNamespace(), ErrValidationTag, rule, details)
+}
+
+// isSecretNamespace returns true if any struct field on the path of
+// validatorNamespace (such as `Config.Map[key].Field`) is a secret.
+func isSecretNamespace[T any](validatorNamespace string) bool {
+	var t T
+	tp := reflect.TypeOf(t)
+	_, goPath := leftmostPathElement(validatorNamespace)
+	for goPath != "" {
+		var element string
+		element, goPath = leftmostPathElement(goPath)
+		fieldName, keys := splitPathKeys(element)
+		for tp.Kind() == reflect.Pointer {
+			tp = tp.Elem()
+		}
+		if tp.Kind() != reflect.Struct {
+			return false
+		}
+		f, ok := tp.FieldByName(fieldName)
+		if !ok {
+			return false
+		}
+		if isSecretField(f) {
+			return true
+		}
+		tp = f.Type
+		for range keys {
+			for tp.Kind() == reflect.Pointer {
+				tp = tp.Elem()
+			}
+			switch tp.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				tp = tp.Elem()
+			default:
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// formatValue formats v for error messages quoting strings.
+func formatValue(v any) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
 // Validate behaves similar to Load and LoadFile just without parsing YAML
 // and instead performing the same type and value checks on t.
 // Validate will obviously not report line:column error location.
 // Validate first validates type T, then validates t according to
 // go-playground/validator struct tags, then recursively
 // invokes all Validate methods returning an error if any.
-func Validate[T any](t T) error {
-	if err := ValidateType[T](); err != nil {
+func Validate[T any](t T, opts ...Option) error {
+	o := newOptions(opts)
+	if err := validateType[T](o); err != nil {
 		return err
 	}
-	err := validator.New(validator.WithRequiredStructEnabled()).Struct(t)
+	return validate(o, t)
+}
+
+// validate performs the checks of Validate on t.
+// Assumes that T has already been validated using ValidateType.
+func validate[T any](o *options, t T) error {
+	err := o.validate.Struct(t)
 	if err != nil {
 		if errs, ok := err.(validator.ValidationErrors); ok {
-			return fmt.Errorf("at %s: %w: %q",
-				errs[0].StructNamespace(), ErrValidationTag, errs[0].Tag())
+			return validationTagErrors[T](o, errs, nil)
 		}
 		return err
 	}
 	typeName := getConfigTypeName(reflect.TypeOf(t))
-	return invokeValidateRecursively(typeName, reflect.ValueOf(t), nil)
+	if err := validateRefs(typeName, reflect.ValueOf(t), nil); err != nil {
+		return err
+	}
+	return invokeValidateRecursively(
+		context.Background(), &t, typeName, reflect.ValueOf(t), nil,
+	)
 }
 
 type Validator interface{ Validate() error }
 
+// ValidatorContext is an alternative to Validator for validations that
+// require access to the whole configuration or need to perform I/O.
+// root is a pointer to the root configuration struct (*T).
+// ctx is the context passed to LoadContext or LoadFileContext and
+// context.Background() otherwise.
+type ValidatorContext interface {
+	Validate(ctx context.Context, root any) error
+}
+
+// FieldError can be returned by Validate methods to report an error
+// of a field of the validated value instead of the value itself,
+// which makes yamagiconf report the error at the line:column of the field.
+type FieldError struct {
+	// Field is the Go path of the field relative to the validated value,
+	// like `Cert`, `TLS.Cert`, `Servers[1].Host` or `Labels[key]`.
+	Field string
+	Err   error
+}
+
+// FieldErr returns a *FieldError reporting err at field.
+func FieldErr(field string, err error) error {
+	return &FieldError{Field: field, Err: err}
+}
+
+func (e *FieldError) Error() string { return e.Field + ": " + e.Err.Error() }
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
 // asIface[I any] returns nil if v doesn't implement the Validator interface
 // neither as a copy- nor as a pointer receiver.
 func asIface[I any](v reflect.Value, allocateIfNecessary bool) (i I) {
@@ -276,20 +527,21 @@ func getConfigTypeName(t reflect.Type) string {
 }
 
 // invokeValidateRecursively runs the Validate method for
-// every field of type that implements the Validator interface recursively.
+// every field of type that implements either the Validator or the
+// ValidatorContext interface recursively.
 // Assumes type of v was validated first using ValidateType.
 // If node != nil then assumes validateYAMLValues was ran first on it.
-func invokeValidateRecursively(path string, v reflect.Value, node *yaml.Node) error {
+func invokeValidateRecursively(
+	ctx context.Context, root any, path string, v reflect.Value, node *yaml.Node,
+) error {
 	tp := v.Type()
 
-	if v := asIface[Validator](v, false); v != nil {
-		if err := v.Validate(); err != nil {
-			if node == nil {
-				return fmt.Errorf("at %s: %w: %w", path, ErrValidation, err)
-			}
-			return fmt.Errorf("at %d:%d: at %s: %w: %w",
-				node.Line, node.Column, path, ErrValidation, err)
+	if err := invokeValidate(ctx, root, v); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+			// Loading was canceled, this isn't a validation error.
+			return fmt.Errorf("at %s: %w", path, err)
 		}
+		return errAtNode(ErrValidation, tp, path, node, err)
 	}
 	for tp.Kind() == reflect.Pointer {
 		if v.IsNil() {
@@ -304,22 +556,18 @@ func invokeValidateRecursively(path string, v reflect.Value, node *yaml.Node) er
 
 	switch tp.Kind() {
 	case reflect.Struct:
-		for i := range tp.NumField() {
-			ft := tp.Field(i)
-			if !ft.IsExported() {
-				continue
-			}
-			fv := v.Field(i)
-			yamlTag := getYAMLFieldName(ft.Tag)
+		for _, ft := range getStructInfo(tp).Fields {
+			fv := v.FieldByIndex(ft.Index)
 			var nodeValue *yaml.Node
-			if node != nil && yamlTag != "-" {
+			if node != nil && ft.YAMLTag != "-" {
 				nodeValue = node
 				if !ft.Anonymous {
-					nodeValue = findContentNodeByTag(node, yamlTag)
+					nodeValue = findContentNodeByTag(node, ft.YAMLTag)
 				}
 			}
 			path := path + "." + ft.Name
-			if err := invokeValidateRecursively(path, fv, nodeValue); err != nil {
+			err := invokeValidateRecursively(ctx, root, path, fv, nodeValue)
+			if err != nil {
 				return err
 			}
 		}
@@ -333,7 +581,7 @@ func invokeValidateRecursively(path string, v reflect.Value, node *yaml.Node) er
 			if node != nil {
 				nodeItem = node.Content[i]
 			}
-			err := invokeValidateRecursively(path, v.Index(i), nodeItem)
+			err := invokeValidateRecursively(ctx, root, path, v.Index(i), nodeItem)
 			if err != nil {
 				return err
 			}
@@ -346,12 +594,12 @@ func invokeValidateRecursively(path string, v reflect.Value, node *yaml.Node) er
 		mapKeys := mapKeysSorted(v)
 		if node == nil {
 			for _, k := range mapKeys {
-				err := invokeValidateRecursively(path, k, nil)
+				err := invokeValidateRecursively(ctx, root, path, k, nil)
 				if err != nil {
 					return err
 				}
 				path := fmt.Sprintf("%s[%v]", path, k)
-				err = invokeValidateRecursively(path, v.MapIndex(k), nil)
+				err = invokeValidateRecursively(ctx, root, path, v.MapIndex(k), nil)
 				if err != nil {
 					return err
 				}
@@ -362,13 +610,15 @@ func invokeValidateRecursively(path string, v reflect.Value, node *yaml.Node) er
 					if k.String() != node.Content[i].Value {
 						continue
 					}
-					err := invokeValidateRecursively(path, k, node.Content[i])
+					err := invokeValidateRecursively(
+						ctx, root, path, k, node.Content[i],
+					)
 					if err != nil {
 						return err
 					}
 					path := fmt.Sprintf("%s[%v]", path, k)
 					err = invokeValidateRecursively(
-						path, v.MapIndex(k), node.Content[i+1],
+						ctx, root, path, v.MapIndex(k), node.Content[i+1],
 					)
 					if err != nil {
 						return err
@@ -380,6 +630,51 @@ func invokeValidateRecursively(path string, v reflect.Value, node *yaml.Node) er
 	return nil
 }
 
+// errAtNode returns err wrapped in kind reported at the line:column of node,
+// or at path if node == nil. If err is a *FieldError then it's reported
+// at the field of the value of type tp instead.
+func errAtNode(
+	kind error, tp reflect.Type, path string, node *yaml.Node, err error,
+) error {
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		// Report the error at the field instead of the value.
+		err = fieldErr.Err
+		if node != nil {
+			node, _ = findNodeByGoPath(tp, resolveAlias(node), fieldErr.Field)
+		}
+		if strings.HasPrefix(fieldErr.Field, "[") {
+			path += fieldErr.Field
+		} else {
+			path += "." + fieldErr.Field
+		}
+	}
+	if node == nil {
+		return fmt.Errorf("at %s: %w: %w", path, kind, err)
+	}
+	return fmt.Errorf("at %d:%d: at %s: %w: %w",
+		node.Line, node.Column, path, kind, err)
+}
+
+// invokeValidate calls the Validate method of v if v implements
+// either Validator or ValidatorContext.
+// Returns the error of ctx without calling Validate if ctx is done.
+func invokeValidate(ctx context.Context, root any, v reflect.Value) error {
+	if i := asIface[Validator](v, false); i != nil {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return i.Validate()
+	}
+	if i := asIface[ValidatorContext](v, false); i != nil {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return i.Validate(ctx, root)
+	}
+	return nil
+}
+
 func newDecoderYAML[S string | []byte](s S) *yaml.Decoder {
 	var reader io.Reader
 	switch s := any(s).(type) {
@@ -395,15 +690,30 @@ func newDecoderYAML[S string | []byte](s S) *yaml.Decoder {
 // was specified for any given field.
 // Assumes that the config type has already been validated.
 func unmarshalEnv(path, envVar string, v reflect.Value) error {
+	env, ok := os.LookupEnv(envVar)
+	return unmarshalEnvValue(path, envVar, env, ok, v)
+}
+
+// unmarshalEnvValue behaves like unmarshalEnv but overwrites v with env
+// only if ok, where env is the value of env var envVar.
+func unmarshalEnvValue(path, envVar, env string, ok bool, v reflect.Value) error {
 	tp := v.Type()
 
+	if tp, isSecret := secretValueType(tp); isSecret {
+		err := unmarshalEnvValue(path, envVar, env, ok, secretValueOf(v))
+		if err != nil {
+			// Don't wrap err to avoid revealing the secret.
+			return errUnmarshalEnv(path, envVar, tp, nil)
+		}
+		return nil
+	}
+
 	textUnmarshaler := asIface[encoding.TextUnmarshaler](v, true)
 	if isPtr := tp.Kind() == reflect.Pointer; isPtr &&
 		tp.Elem().Kind() == reflect.Struct && !v.IsNil() && textUnmarshaler == nil {
 		// Pointer to a struct type that doesn't implement encoding.TextUnmarshaler
 		v, tp = v.Elem(), tp.Elem()
 	} else if isPtr {
-		env, ok := os.LookupEnv(envVar)
 		if ok {
 			if env == "null" {
 				v.Set(reflect.Zero(v.Type()))
@@ -423,7 +733,6 @@ func unmarshalEnv(path, envVar string, v reflect.Value) error {
 	}
 
 	if textUnmarshaler != nil {
-		env, ok := os.LookupEnv(envVar)
 		if !ok {
 			return nil
 		}
@@ -433,7 +742,6 @@ func unmarshalEnv(path, envVar string, v reflect.Value) error {
 	}
 
 	if tp == typeTimeDuration {
-		env, ok := os.LookupEnv(envVar)
 		if !ok {
 			return nil
 		}
@@ -447,7 +755,6 @@ func unmarshalEnv(path, envVar string, v reflect.Value) error {
 
 	switch tp.Kind() {
 	case reflect.Bool:
-		env, ok := os.LookupEnv(envVar)
 		if !ok {
 			return nil
 		}
@@ -460,13 +767,11 @@ func unmarshalEnv(path, envVar string, v reflect.Value) error {
 			return errUnmarshalEnv(path, envVar, tp, nil)
 		}
 	case reflect.String:
-		env, ok := os.LookupEnv(envVar)
 		if !ok {
 			return nil
 		}
 		v.SetString(env)
 	case reflect.Float32:
-		env, ok := os.LookupEnv(envVar)
 		if !ok {
 			return nil
 		}
@@ -476,7 +781,6 @@ func unmarshalEnv(path, envVar string, v reflect.Value) error {
 		}
 		v.SetFloat(f)
 	case reflect.Float64:
-		env, ok := os.LookupEnv(envVar)
 		if !ok {
 			return nil
 		}
@@ -486,7 +790,6 @@ func unmarshalEnv(path, envVar string, v reflect.Value) error {
 		}
 		v.SetFloat(f)
 	case reflect.Int8:
-		env, ok := os.LookupEnv(envVar)
 		if !ok {
 			return nil
 		}
@@ -496,7 +799,6 @@ func unmarshalEnv(path, envVar string, v reflect.Value) error {
 		}
 		v.SetInt(int64(i))
 	case reflect.Uint8:
-		env, ok := os.LookupEnv(envVar)
 		if !ok {
 			return nil
 		}
@@ -506,7 +808,6 @@ func unmarshalEnv(path, envVar string, v reflect.Value) error {
 		}
 		v.SetUint(uint64(i))
 	case reflect.Int16:
-		env, ok := os.LookupEnv(envVar)
 		if !ok {
 			return nil
 		}
@@ -516,7 +817,6 @@ func unmarshalEnv(path, envVar string, v reflect.Value) error {
 		}
 		v.SetInt(int64(i))
 	case reflect.Uint16:
-		env, ok := os.LookupEnv(envVar)
 		if !ok {
 			return nil
 		}
@@ -526,7 +826,6 @@ func unmarshalEnv(path, envVar string, v reflect.Value) error {
 		}
 		v.SetUint(uint64(i))
 	case reflect.Int32:
-		env, ok := os.LookupEnv(envVar)
 		if !ok {
 			return nil
 		}
@@ -536,7 +835,6 @@ func unmarshalEnv(path, envVar string, v reflect.Value) error {
 		}
 		v.SetInt(int64(i))
 	case reflect.Uint32:
-		env, ok := os.LookupEnv(envVar)
 		if !ok {
 			return nil
 		}
@@ -546,7 +844,6 @@ func unmarshalEnv(path, envVar string, v reflect.Value) error {
 		}
 		v.SetUint(uint64(i))
 	case reflect.Int64:
-		env, ok := os.LookupEnv(envVar)
 		if !ok {
 			return nil
 		}
@@ -556,7 +853,6 @@ func unmarshalEnv(path, envVar string, v reflect.Value) error {
 		}
 		v.SetInt(int64(i))
 	case reflect.Uint64:
-		env, ok := os.LookupEnv(envVar)
 		if !ok {
 			return nil
 		}
@@ -565,15 +861,46 @@ func unmarshalEnv(path, envVar string, v reflect.Value) error {
 			return errUnmarshalEnv(path, envVar, tp, err)
 		}
 		v.SetUint(uint64(i))
+	case reflect.Int:
+		if !ok {
+			return nil
+		}
+		i, err := strconv.ParseInt(env, 10, 0)
+		if err != nil {
+			return errUnmarshalEnv(path, envVar, tp, err)
+		}
+		v.SetInt(i)
+	case reflect.Uint:
+		if !ok {
+			return nil
+		}
+		i, err := strconv.ParseUint(env, 10, 0)
+		if err != nil {
+			return errUnmarshalEnv(path, envVar, tp, err)
+		}
+		v.SetUint(i)
 	case reflect.Struct:
-		for i := range tp.NumField() {
-			f := tp.Field(i)
-			if !f.IsExported() {
-				continue
+		for _, f := range getStructInfo(tp).Fields {
+			path := path + "." + f.Name
+			envVar := f.Tag.Get("env")
+			env, ok := "", false
+			switch name, isFile := fieldEnvVar(f.StructField); {
+			case isFile:
+				b, err := os.ReadFile(os.Getenv(name))
+				if err != nil {
+					return fmt.Errorf("at %s: %w %s: %w",
+						path, ErrEnvInvalidVar, name, err)
+				}
+				envVar, env, ok = name, strings.TrimRight(string(b), "\r\n"), true
+			case name != "":
+				env, ok = os.Getenv(name), true
 			}
-			n := f.Tag.Get("env")
-			err := unmarshalEnv(path+"."+f.Name, n, v.Field(i))
-			if err != nil {
+			err := unmarshalEnvValue(path, envVar, env, ok, v.FieldByIndex(f.Index))
+			if _, isSecret := secretValueType(f.Type); err != nil && ok &&
+				!isSecret && isSecretField(f.StructField) {
+				// Don't wrap err to avoid revealing the secret.
+				return errUnmarshalEnv(path, envVar, f.Type, nil)
+			} else if err != nil {
 				return err
 			}
 		}
@@ -624,7 +951,9 @@ func errUnmarshalEnv(path, envVar string, tp reflect.Type, err error) error {
 }
 
 // mustFindLocationByValidatorNamespace finds the line and column numbers of the
-// validator namespace (field type path).
+// validator namespace (field type path) such as `T.Map[key].Slice[0].Field`.
+// Returns the location of the deepest node that could be resolved and the
+// yaml tag of the last struct field on the path.
 func mustFindLocationByValidatorNamespace[T any](
 	validatorNamespace string, rootNode *yaml.Node,
 ) (line int, column int, yamlTag string) {
@@ -634,39 +963,154 @@ func mustFindLocationByValidatorNamespace[T any](
 	// Remove the type prefix, assuming validatorNamespace starts with the type name
 	_, validatorNamespace = leftmostPathElement(validatorNamespace)
 
-	currentTp, currentNode := tp, rootNode.Content[0]
-	var fieldName string
+	node, yamlTag := findNodeByGoPath(tp, rootNode.Content[0], validatorNamespace)
+	return node.Line, node.Column, yamlTag
+}
+
+// findNodeByGoPath finds the node of goPath (such as `Map[key].Slice[0].Field`)
+// relative to node of type tp.
+// Returns the deepest node that could be resolved and the yaml tag
+// of the last struct field on the path.
+func findNodeByGoPath(
+	tp reflect.Type, node *yaml.Node, goPath string,
+) (*yaml.Node, string) {
+	var element, yamlTag string
 
 FOR_PATH:
-	for {
-		fieldName, validatorNamespace = leftmostPathElement(validatorNamespace)
-		if fieldName == "" {
-			break
-		}
-		f, _ := currentTp.FieldByName(fieldName)
-		yamlTag = getYAMLFieldName(f.Tag)
-		if yamlTag == "-" {
-			continue // Ignored field.
+	for goPath != "" {
+		element, goPath = leftmostPathElement(goPath)
+		fieldName, keys := splitPathKeys(element)
+		if fieldName != "" {
+			for tp.Kind() == reflect.Pointer {
+				tp = tp.Elem()
+			}
+			if tp.Kind() != reflect.Struct {
+				break
+			}
+			f, ok := tp.FieldByName(fieldName)
+			if !ok {
+				break
+			}
+			tp = f.Type
+			if f.Anonymous {
+				continue // Inline fields share the node with their parent.
+			}
+			yamlTag = getYAMLFieldName(f.Tag)
+			if yamlTag == "-" {
+				break // Ignored field.
+			}
+			n := findContentNodeByTag(resolveAlias(node), yamlTag)
+			if n == nil {
+				break // Not found
+			}
+			node = n
 		}
-		for i := 0; i < len(currentNode.Content); i += 2 {
-			if currentNode.Content[i].Value == yamlTag {
-				currentTp = f.Type
-				currentNode = currentNode.Content[i+1]
-				continue FOR_PATH
+		for _, key := range keys {
+			node = resolveAlias(node)
+			for tp.Kind() == reflect.Pointer {
+				tp = tp.Elem()
 			}
+			switch tp.Kind() {
+			case reflect.Slice, reflect.Array:
+				i, err := strconv.Atoi(key)
+				if err != nil || i < 0 || i >= len(node.Content) ||
+					node.Kind != yaml.SequenceNode {
+					break FOR_PATH
+				}
+				node = node.Content[i]
+			case reflect.Map:
+				n := findMapValueNode(node, key)
+				if n == nil {
+					break FOR_PATH
+				}
+				node = n
+			default:
+				break FOR_PATH
+			}
+			tp = tp.Elem()
 		}
-		break // Not found
 	}
-	return currentNode.Line, currentNode.Column, yamlTag
+	return node, yamlTag
 }
 
+// leftmostPathElement splits s at the first dot that isn't enclosed
+// in square brackets (which contain slice indexes and map keys).
 func leftmostPathElement(s string) (element, rest string) {
-	if i := strings.IndexByte(s, '.'); i != -1 {
-		return s[:i], s[i+1:]
+	depth := 0
+	for i := range len(s) {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				return s[:i], s[i+1:]
+			}
+		}
 	}
 	return s, ""
 }
 
+// splitPathKeys splits a path element like `Field[0][key]` into
+// the field name and the list of slice indexes and map keys.
+func splitPathKeys(element string) (fieldName string, keys []string) {
+	i := strings.IndexByte(element, '[')
+	if i == -1 {
+		return element, nil
+	}
+	fieldName, element = element[:i], element[i:]
+	for len(element) > 1 && element[0] == '[' {
+		depth, end := 0, -1
+		for i := range len(element) {
+			if element[i] == '[' {
+				depth++
+			} else if element[i] == ']' {
+				if depth--; depth == 0 {
+					end = i
+					break
+				}
+			}
+		}
+		if end == -1 {
+			break
+		}
+		keys = append(keys, element[1:end])
+		element = element[end+1:]
+	}
+	return fieldName, keys
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	if node.Alias != nil {
+		return node.Alias
+	}
+	return node
+}
+
+// findMapValueNode returns the value node of key in mapping node
+// or nil if there is no such key.
+func findMapValueNode(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// yamlValidation is the state of validateYAMLValues for a single document.
+type yamlValidation struct {
+	opts    *options
+	anchors map[string]*anchor
+
+	// defaults are the value nodes added by applyDefaults.
+	defaults map[*yaml.Node]struct{}
+}
+
 type anchor struct {
 	*yaml.Node
 	Defined bool
@@ -676,9 +1120,12 @@ type anchor struct {
 // validateYAMLValues returns an error if the yaml model contains illegal values
 // or is missing values specified by T. Assumes that tp has already been validated.
 func validateYAMLValues(
-	anchors map[string]*anchor, yamlTag, path string, tp reflect.Type, node *yaml.Node,
+	v *yamlValidation, yamlTag, path string, tp reflect.Type, node *yaml.Node,
 ) error {
-	if err := validateValue(tp, node); err != nil {
+	if t, ok := secretValueType(tp); ok {
+		tp = t // Secrets are subject to the same rules as the values they wrap.
+	}
+	if err := validateValue(&v.opts.rules, tp, node); err != nil {
 		if yamlTag != "" {
 			return fmt.Errorf("at %d:%d: %q (%s): %w",
 				node.Line, node.Column, yamlTag, path, err)
@@ -687,8 +1134,13 @@ func validateYAMLValues(
 			node.Line, node.Column, path, err)
 	}
 
+	if v.opts.rules.ForbidAnchors && (node.Anchor != "" || node.Alias != nil) {
+		return fmt.Errorf("at %d:%d: %w", node.Line, node.Column, ErrYAMLAnchorUsed)
+	}
 	if node.Anchor != "" {
-		if p, ok := anchors[node.Anchor]; ok && p.Defined {
+		// The same node is visited again if it's merged by a merge key.
+		if p, ok := v.anchors[node.Anchor]; ok && p.Defined && p.Node != node &&
+			!v.opts.rules.AllowAnchorRedefinition {
 			return fmt.Errorf("at %d:%d: redefined anchor %q at %d:%d: %w",
 				node.Line, node.Column,
 				node.Anchor,
@@ -696,14 +1148,27 @@ func validateYAMLValues(
 				ErrYAMLAnchorRedefined)
 		}
 		if node.Value == "" && node.Style != yaml.DoubleQuotedStyle &&
-			node.Style != yaml.SingleQuotedStyle && len(node.Content) < 1 {
+			node.Style != yaml.SingleQuotedStyle && len(node.Content) < 1 &&
+			!v.opts.rules.AllowAnchorNoValue {
 			return fmt.Errorf("at %d:%d: anchor %q: %w",
 				node.Line, node.Column, node.Anchor, ErrYAMLAnchorNoValue)
 		}
-		anchors[node.Anchor] = &anchor{Node: node, Defined: true}
+		a, ok := v.anchors[node.Anchor]
+		if !ok {
+			a = new(anchor)
+			v.anchors[node.Anchor] = a
+		}
+		a.Node, a.Defined = node, true
 	}
 	if node.Alias != nil {
-		anchors[node.Alias.Anchor].IsUsed = true
+		a, ok := v.anchors[node.Alias.Anchor]
+		if !ok {
+			// The alias is visited before the anchor because fields are
+			// traversed in the order of the Go struct, not the YAML document.
+			a = &anchor{Node: node.Alias}
+			v.anchors[node.Alias.Anchor] = a
+		}
+		a.IsUsed = true
 	}
 
 	if implementsInterface[encoding.TextUnmarshaler](tp) &&
@@ -712,52 +1177,47 @@ func validateYAMLValues(
 			node.Line, node.Column, ErrYAMLNonStrOnTextUnmarsh, tp.String())
 	}
 
+	if tp.Kind() == reflect.Pointer && node.Kind == yaml.MappingNode {
+		tp = tp.Elem() // Pointer to struct.
+	}
 	switch tp.Kind() {
 	case reflect.Struct:
 		if implementsInterface[encoding.TextUnmarshaler](tp) ||
 			implementsInterface[yaml.Unmarshaler](tp) {
 			return nil
 		}
-		for i := range tp.NumField() {
-			f := tp.Field(i)
-			if !f.IsExported() {
-				continue
-			}
-			yamlTag := getYAMLFieldName(f.Tag)
-			if yamlTag == "-" {
-				continue // Ignored field.
-			}
-			path := path + "." + f.Name
-			contentNode := node
-			if !f.Anonymous {
-				contentNode = findContentNodeByTag(node, yamlTag)
-			}
-			if contentNode == nil {
-				return fmt.Errorf("at %s (as %q): %w",
-					path, yamlTag, ErrYAMLMissingConfig)
-			}
-			for _, n := range contentNode.Content {
-				if n.Tag == "!!merge" {
-					return fmt.Errorf("at %d:%d: %w",
-						n.Line, n.Column, ErrYAMLMergeKey)
-				}
-			}
-			err := validateYAMLValues(anchors, yamlTag, path, f.Type, contentNode)
-			if err != nil {
+		if node.Tag == "!!null" && node.Value != "" {
+			// Null was allowed by validateValue and leaves the zero value.
+			return nil
+		}
+		if err := validateYAMLKeys(v, tp, node); err != nil {
+			return err
+		}
+		if v.opts.rules.StrictKeyOrder {
+			if err := validateYAMLKeyOrder(v.defaults, tp, node); err != nil {
 				return err
 			}
 		}
+		return validateYAMLFields(v, path, tp, node)
 	case reflect.Slice, reflect.Array:
 		tp := tp.Elem()
 		for index, node := range node.Content {
-			if node.Tag == "!!null" && node.Value == "" {
+			if node.Tag == "!!null" && node.Value == "" &&
+				!v.opts.rules.AllowEmptyArrayItems {
 				// If it's a null item with no value then no zero value item would be
 				// appended to a Go slice.
 				return fmt.Errorf("at %d:%d: %q (%s): %w",
 					node.Line, node.Column, yamlTag, path, ErrYAMLEmptyArrayItem)
 			}
 			path := fmt.Sprintf("%s[%d]", path, index)
-			if err := validateYAMLValues(anchors, yamlTag, path, tp, node); err != nil {
+			if n := resolveAlias(node); n.Tag == "!!null" && n.Value != "" &&
+				!isNilableKind(tp.Kind()) && zeroNode(tp) == nil {
+				// The decoder would drop the item instead of appending
+				// a zero value item (see zeroNullItems).
+				return fmt.Errorf("at %d:%d: %q (%s): %w",
+					node.Line, node.Column, yamlTag, path, ErrYAMLNullOnNonPointer)
+			}
+			if err := validateYAMLValues(v, yamlTag, path, tp, node); err != nil {
 				return err
 			}
 		}
@@ -765,13 +1225,21 @@ func validateYAMLValues(
 		tpKey, tpVal := tp.Key(), tp.Elem()
 		for i := 0; i < len(node.Content); i += 2 {
 			path := fmt.Sprintf("%s[%q]", path, node.Content[i].Value)
+			if c := v.opts.rules.MapKeyConvention; tpKey.Kind() == reflect.String &&
+				!implementsInterface[encoding.TextUnmarshaler](tpKey) &&
+				!c.matches(node.Content[i].Value) {
+				k := node.Content[i]
+				return fmt.Errorf("at %d:%d: %s: %w: %q isn't %s, use %q",
+					k.Line, k.Column, path, ErrYAMLKeyConvention,
+					k.Value, c, c.convert(k.Value))
+			}
 			// Validate key
-			err := validateYAMLValues(anchors, yamlTag, path, tpKey, node.Content[i])
+			err := validateYAMLValues(v, yamlTag, path, tpKey, node.Content[i])
 			if err != nil {
 				return err
 			}
 			// Validate value
-			err = validateYAMLValues(anchors, yamlTag, path, tpVal, node.Content[i+1])
+			err = validateYAMLValues(v, yamlTag, path, tpVal, node.Content[i+1])
 			if err != nil {
 				return err
 			}
@@ -780,30 +1248,139 @@ func validateYAMLValues(
 	return nil
 }
 
-func validateValue(tp reflect.Type, node *yaml.Node) error {
-	if node.Style == yaml.TaggedStyle {
-		return fmt.Errorf("tag %q: %w", node.Tag, ErrYAMLTagUsed)
+// validateYAMLKeys returns an error if mapping node contains any key
+// that isn't specified by struct type tp.
+// Aliases of merge keys are marked as used if merge keys are allowed.
+func validateYAMLKeys(v *yamlValidation, tp reflect.Type, node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
 	}
-	kind := tp.Kind()
-	if kind == reflect.String && node.Value == "null" {
-		switch node.Style {
-		case yaml.DoubleQuotedStyle, yaml.SingleQuotedStyle:
-			return nil
-		default:
-			return ErrYAMLNullOnNonPointer
+	info := getStructInfo(tp)
+	for i := 0; i < len(node.Content); i += 2 {
+		n := node.Content[i]
+		if n.Tag == "!!merge" {
+			if !v.opts.rules.AllowMergeKeys {
+				return fmt.Errorf("at %d:%d: %w", n.Line, n.Column, ErrYAMLMergeKey)
+			}
+			if err := validateMergeKey(v, node.Content[i+1]); err != nil {
+				return err
+			}
+			continue
+		}
+		if info.InlineMap {
+			continue
+		}
+		if _, ok := info.Keys[n.Value]; !ok {
+			return fmt.Errorf("at %d:%d: %w: field %q not found in type %s",
+				n.Line, n.Column, ErrYAMLMalformed, n.Value, tp.String())
+		}
+	}
+	for _, m := range mergedMappings(node) {
+		if err := validateYAMLKeys(v, tp, m); err != nil {
+			return err
 		}
 	}
-	if v := node.Value; v == "~" || strings.EqualFold(v, "null") {
-		if v != "null" {
+	return nil
+}
+
+// validateMergeKey marks the aliases of the value of a merge key as used.
+func validateMergeKey(v *yamlValidation, value *yaml.Node) error {
+	aliases := []*yaml.Node{value}
+	if value.Kind == yaml.SequenceNode {
+		aliases = value.Content
+	}
+	for _, n := range aliases {
+		if n.Alias == nil {
+			continue
+		}
+		if v.opts.rules.ForbidAnchors {
+			return fmt.Errorf("at %d:%d: %w", n.Line, n.Column, ErrYAMLAnchorUsed)
+		}
+		a, ok := v.anchors[n.Alias.Anchor]
+		if !ok {
+			a = &anchor{Node: n.Alias}
+			v.anchors[n.Alias.Anchor] = a
+		}
+		a.IsUsed = true
+	}
+	return nil
+}
+
+// validateYAMLFields validates the values of all fields of struct type tp
+// including the fields of embedded inline structs.
+func validateYAMLFields(
+	v *yamlValidation, path string, tp reflect.Type, node *yaml.Node,
+) error {
+	for _, f := range getStructInfo(tp).Fields {
+		if f.YAMLTag == "-" {
+			continue // Ignored field.
+		}
+		path := path + "." + f.Name
+		if f.Anonymous {
+			// Inline fields share the mapping node with their parent.
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			var err error
+			if isPlainStruct(embedded) {
+				err = validateYAMLFields(v, path, embedded, node)
+			} else {
+				err = validateYAMLValues(v, f.YAMLTag, path, f.Type, node)
+			}
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		contentNode := findContentNodeByTag(node, f.YAMLTag)
+		if contentNode == nil {
+			return fmt.Errorf("at %s (as %q): %w",
+				path, f.YAMLTag, ErrYAMLMissingConfig)
+		}
+		for _, n := range contentNode.Content {
+			if n.Tag == "!!merge" && !v.opts.rules.AllowMergeKeys {
+				return fmt.Errorf("at %d:%d: %w",
+					n.Line, n.Column, ErrYAMLMergeKey)
+			}
+		}
+		if isEnvOnlySecretField(f.StructField) {
+			err := validateEnvOnlySecret(f.YAMLTag, path, contentNode)
+			if err != nil {
+				return err
+			}
+		}
+		err := validateYAMLValues(v, f.YAMLTag, path, f.Type, contentNode)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateValue(r *Rules, tp reflect.Type, node *yaml.Node) error {
+	if node.Style == yaml.TaggedStyle && !r.AllowYAMLTags {
+		return fmt.Errorf("tag %q: %w", node.Tag, ErrYAMLTagUsed)
+	}
+	kind := tp.Kind()
+	// Quoted scalars are always strings, never nulls. Rejecting them would
+	// leave strings like "~" or "NULL" without any representation Marshal
+	// could write for them.
+	isQuoted := node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0
+	if v := node.Value; !isQuoted && (v == "~" || strings.EqualFold(v, "null")) {
+		if v != "null" && !r.AllowNullVariants {
 			return ErrYAMLBadNullLiteral
 		}
 		switch kind {
 		case reflect.Pointer, reflect.Slice, reflect.Map:
 		default:
-			return ErrYAMLNullOnNonPointer
+			if node.Tag == "!!null" && !r.AllowNullOnNonPointer {
+				return ErrYAMLNullOnNonPointer
+			}
 		}
 	}
-	if kind == reflect.Bool && node.Alias == nil {
+	if kind == reflect.Bool && node.Alias == nil && node.Tag != "!!null" &&
+		!r.AllowBoolVariants {
 		switch node.Value {
 		case "true", "false", "":
 		default:
@@ -818,8 +1395,9 @@ func validateValue(tp reflect.Type, node *yaml.Node) error {
 //   - T contains any struct field with an invalid "env" struct tag.
 //   - T is recursive.
 //   - T contains any unsupported types (signed and unsigned integers with unspecified
-//     width, interface (including `any`), function, channel,
-//     unsafe.Pointer, pointer to pointer, pointer to slice, pointer to map).
+//     width unless Rules.AllowIntUint, interface (including `any`), function,
+//     channel, unsafe.Pointer, pointer to pointer, pointer to slice,
+//     pointer to map).
 //   - T is not a struct or implements yaml.Unmarshaler or encoding.TextUnmarshaler.
 //   - T contains any structs with no exported fields.
 //   - T contains any structs with yaml and/or env tags assigned to unexported fields.
@@ -827,10 +1405,48 @@ func validateValue(tp reflect.Type, node *yaml.Node) error {
 //     encoding.TextUnmarshaler that contains fields with yaml or env struct tags.
 //   - T contains any fields with env tag on a type that implements yaml.Unmarshaler.
 //   - T contains any struct containing multiple fields with the same yaml tag.
-func ValidateType[T any]() error {
+//   - T contains any struct field with a `ref` struct tag on a field type other
+//     than string, pointer to string, slice or array of strings or map with
+//     string keys, or the path of which doesn't refer to a map with string keys.
+//   - T contains any struct field with a `default` struct tag the value of which
+//     doesn't comply with the rules of LoadFile for the type of the field,
+//     or on an ignored, embedded or unexported field.
+//   - T contains any struct field with a `secret` struct tag other than
+//     `secret:"true"` and `secret:"env-only"`, or with `secret:"env-only"`
+//     but without an `env` struct tag or with a `default` struct tag.
+//   - T contains any struct field with a go-playground/validator struct tag
+//     the validator can't parse (like undefined validations), with `dive`
+//     applying to a type other than slice, array or map or with `keys`
+//     applying to a type other than map.
+//     The validator provided with WithValidator is used if any, which makes
+//     custom validations and aliases known. The tags of all exported fields
+//     including fields ignored by YAML are parsed without running any
+//     validations. Rules registered using RegisterStructValidationMapRules
+//     and tags of unexported fields aren't checked.
+//   - T contains any yaml struct tag that doesn't follow the naming convention
+//     of Rules.YAMLTagConvention (see WithYAMLTagConvention).
+//
+// Returns ErrTranslatorWithoutValidator if opts contain WithTranslator
+// but not WithValidator.
+func ValidateType[T any](opts ...Option) error {
+	return validateType[T](newOptions(opts))
+}
+
+func validateType[T any](o *options) error {
+	if o.translator != nil && o.validate == defaultValidator() {
+		// The shared default validator has no translations registered
+		// and must not be modified.
+		return ErrTranslatorWithoutValidator
+	}
+	var t T
+	rootType := reflect.TypeOf(t)
 	stack := []reflect.Type{}
+	ignored := map[reflect.Type]struct{}{}
 	var traverse func(path string, tp reflect.Type) error
 	traverse = func(path string, tp reflect.Type) error {
+		if t, ok := secretValueType(tp); ok {
+			return traverse(path, t)
+		}
 		if implementsInterface[encoding.TextUnmarshaler](tp) ||
 			implementsInterface[yaml.Unmarshaler](tp) {
 			return validateTypeImplementingIfaces(path, tp)
@@ -869,9 +1485,31 @@ func ValidateType[T any]() error {
 					}
 				}
 
-				if err := validateEnvField(f); err != nil {
+				if err := validateEnvField(&o.rules, f); err != nil {
+					return fmt.Errorf("at %s: %w", path, err)
+				}
+				if err := validateRefTag(rootType, f); err != nil {
 					return fmt.Errorf("at %s: %w", path, err)
 				}
+				if err := validateSecretTag(f); err != nil {
+					return fmt.Errorf("at %s: %w", path, err)
+				}
+				if err := validateEnvOnlySecretDefault(f); err != nil {
+					return fmt.Errorf("at %s: %w", path, err)
+				}
+
+				if _, ok := f.Tag.Lookup("default"); ok &&
+					(yamlIgnored || f.Anonymous || !isExported) {
+					return fmt.Errorf("at %s: %w: on ignored, embedded or "+
+						"unexported field", path, ErrTypeInvalidDefaultTag)
+				}
+
+				if isExported && yamlIgnored {
+					err := validateIgnoredValidateTags(o.validate, path, f.Type, ignored)
+					if err != nil {
+						return err
+					}
+				}
 
 				hasEnvTag := f.Tag.Get("env") != ""
 				if !isExported || (yamlIgnored && !hasEnvTag) {
@@ -885,6 +1523,11 @@ func ValidateType[T any]() error {
 				// Avoid checking tag redifinition for embedded fields.
 				// For embedded fields yamlTag will always be == "".
 				if yamlTag != "" {
+					if c := o.rules.YAMLTagConvention; !c.matches(yamlTag) {
+						return fmt.Errorf("at %s: %w: %q isn't %s, use %q",
+							path, ErrTypeYAMLTagConvention,
+							yamlTag, c, c.convert(yamlTag))
+					}
 					if previous, ok := yamlTags[yamlTag]; ok {
 						return fmt.Errorf(
 							"at %s: yaml tag %q previously defined on field %s: %w",
@@ -896,10 +1539,18 @@ func ValidateType[T any]() error {
 				if err != nil {
 					return err
 				}
+				if err := validateDefaultTag(o, path, f); err != nil {
+					return fmt.Errorf("at %s: %w", path, err)
+				}
 			}
 			if exportedFields < 1 {
 				return fmt.Errorf("at %s: %w", path, ErrTypeNoExportedFields)
 			}
+			// Validate tags only after all field types were traversed
+			// to report invalid tags of nested types first.
+			if err := validateValidateTags(o.validate, path, tp); err != nil {
+				return err
+			}
 			stack = stack[:len(stack)-1] // Pop stack
 			return nil
 		case reflect.Chan,
@@ -915,11 +1566,17 @@ func ValidateType[T any]() error {
 			}
 			return traverse(path, tp)
 		case reflect.Int:
+			if o.rules.AllowIntUint {
+				return nil
+			}
 			return fmt.Errorf("at %s: %w: %s, %s",
 				path, ErrTypeUnsupported, tp.String(),
 				"use integer type with specified width, "+
 					"such as int8, int16, int32 or int64 instead of int")
 		case reflect.Uint:
+			if o.rules.AllowIntUint {
+				return nil
+			}
 			return fmt.Errorf("at %s: %w: %s, %s",
 				path, ErrTypeUnsupported, tp.String(),
 				"use unsigned integer type with specified width, "+
@@ -934,8 +1591,7 @@ func ValidateType[T any]() error {
 		}
 		return nil
 	}
-	var t T
-	tp := reflect.TypeOf(t)
+	tp := rootType
 
 	n := tp.Name()
 	if n == "" {
@@ -950,6 +1606,164 @@ func ValidateType[T any]() error {
 	return traverse(n, tp)
 }
 
+var regexValidatorPanicField = regexp.MustCompile(`on field '([^']*)'`)
+
+// validateValidateTags returns an error if v fails to parse any validate
+// struct tag of struct type tp. Since the validator doesn't expose the set of
+// known validations and panics when encountering an invalid tag, the zero
+// value of a struct type mirroring the exported fields of tp is validated
+// with all fields filtered out recovering from the panic. The validator parses
+// all tags of the mirror but runs neither field nor struct level validations
+// since none are registered for it. Rules registered for tp using
+// RegisterStructValidationMapRules aren't checked.
+// Assumes that the field types of tp were checked before.
+func validateValidateTags(
+	v *validator.Validate, path string, tp reflect.Type,
+) (err error) {
+	var fields []reflect.StructField
+	var names []string // Names of the fields of tp by mirror field index.
+	for i := range tp.NumField() {
+		f := tp.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue // Ignored by the validator.
+		}
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("F%d", len(fields)),
+			Type: f.Type,
+			Tag:  f.Tag,
+		})
+		names = append(names, f.Name)
+	}
+	if len(fields) < 1 {
+		return nil
+	}
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		msg := fmt.Sprint(r)
+		if m := regexValidatorPanicField.FindStringSubmatchIndex(msg); m != nil {
+			var i int
+			if _, err := fmt.Sscanf(msg[m[2]:m[3]], "F%d", &i); err == nil &&
+				i < len(names) {
+				path += "." + names[i]
+				msg = msg[:m[2]] + names[i] + msg[m[3]:]
+			}
+		}
+		err = fmt.Errorf("at %s: %w: %s", path, ErrTypeInvalidValidateTag, msg)
+	}()
+	_ = v.StructFiltered(
+		reflect.New(reflect.StructOf(fields)).Interface(),
+		func([]byte) bool { return true },
+	)
+
+	// The validator only panics on misplaced dive and keys tags when it
+	// traverses a value, which would require running the validations.
+	tagName := validatorTagName(v)
+	for i, f := range fields {
+		tag := f.Tag.Get(tagName)
+		if tag == "" || tag == "-" {
+			continue
+		}
+		if msg := checkDiveTags(f.Type, strings.Split(tag, ",")); msg != "" {
+			return fmt.Errorf("at %s.%s: %w: %s",
+				path, names[i], ErrTypeInvalidValidateTag, msg)
+		}
+	}
+	return nil
+}
+
+// validatorTagName returns the struct tag name used by v.
+// The validator doesn't expose it, so it's read from its unexported field
+// falling back to the default "validate".
+func validatorTagName(v *validator.Validate) string {
+	f := reflect.ValueOf(v).Elem().FieldByName("tagName")
+	if f.Kind() != reflect.String || f.String() == "" {
+		return "validate"
+	}
+	return f.String()
+}
+
+// checkDiveTags returns a description of the problem if any `dive` tag
+// in tags applies to a type that isn't a slice, array or map or any `keys`
+// tag applies to a type that isn't a map. tp is the type tags apply to.
+// Aliases aren't expanded.
+func checkDiveTags(tp reflect.Type, tags []string) string {
+	for i := 0; i < len(tags); i++ {
+		if tags[i] != "dive" {
+			continue
+		}
+		for tp.Kind() == reflect.Pointer {
+			tp = tp.Elem()
+		}
+		switch tp.Kind() {
+		case reflect.Interface:
+			return "" // Unknown until runtime.
+		case reflect.Slice, reflect.Array:
+			if i+1 < len(tags) && tags[i+1] == "keys" {
+				return fmt.Sprintf("'keys' can't be used on %s, "+
+					"only on maps", tp.String())
+			}
+		case reflect.Map:
+			if i+1 < len(tags) && tags[i+1] == "keys" {
+				end := slices.Index(tags[i+2:], "endkeys")
+				if end < 0 {
+					end = len(tags) - (i + 2)
+				}
+				keyTags := tags[i+2 : i+2+end]
+				if msg := checkDiveTags(tp.Key(), keyTags); msg != "" {
+					return msg
+				}
+				i += 2 + end
+			}
+		default:
+			return fmt.Sprintf("'dive' can't be used on %s, "+
+				"only on slices, arrays and maps", tp.String())
+		}
+		tp = tp.Elem()
+	}
+	return ""
+}
+
+// validateIgnoredValidateTags calls validateValidateTags for all struct types
+// reachable from type tp of a field ignored by YAML, which aren't checked by
+// ValidateType otherwise. visited prevents checking types more than once.
+func validateIgnoredValidateTags(
+	v *validator.Validate, path string, tp reflect.Type,
+	visited map[reflect.Type]struct{},
+) error {
+	for {
+		switch tp.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			tp = tp.Elem()
+			continue
+		}
+		break
+	}
+	if tp.Kind() != reflect.Struct {
+		return nil
+	}
+	if _, ok := visited[tp]; ok {
+		return nil
+	}
+	visited[tp] = struct{}{}
+	if err := validateValidateTags(v, path, tp); err != nil {
+		return err
+	}
+	for i := range tp.NumField() {
+		f := tp.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		err := validateIgnoredValidateTags(v, path+"."+f.Name, f.Type, visited)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // validateTypeImplementingIfaces assumes that implementer is
 // implementing either encoding.TextUnmarshaler or yaml.Unmarshaler
 func validateTypeImplementingIfaces(path string, implementer reflect.Type) error {
@@ -977,15 +1791,108 @@ func validateTypeImplementingIfaces(path string, implementer reflect.Type) error
 }
 
 func findContentNodeByTag(node *yaml.Node, yamlTag string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
 	// Find value node
-	for i, n := range node.Content {
-		if n.Value == yamlTag {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if n := node.Content[i]; n.Value == yamlTag && n.Tag != "!!merge" {
 			return node.Content[i+1] // The value node is the next node
 		}
 	}
+	// Keys of the mapping itself override merged keys.
+	for _, m := range mergedMappings(node) {
+		if n := findContentNodeByTag(m, yamlTag); n != nil {
+			return n
+		}
+	}
 	return nil
 }
 
+// structField is the cached metadata of an exported struct field.
+type structField struct {
+	reflect.StructField
+
+	// YAMLTag is the name of the field in the YAML document.
+	// YAMLTag is "-" for ignored fields and "" for embedded inline fields.
+	YAMLTag string
+
+	// Default is the parsed value of the `default` struct tag
+	// or nil if the field has none.
+	Default *yaml.Node
+}
+
+// structInfo is the cached metadata of a struct type.
+type structInfo struct {
+	// Fields are all exported fields in the order of declaration.
+	Fields []structField
+
+	// Keys are the YAML keys of all fields including those of embedded
+	// inline structs.
+	Keys map[string]struct{}
+
+	// Order are the YAML keys of all fields including those of embedded
+	// inline structs in the order of declaration.
+	Order []string
+
+	// InlineMap is true if the struct embeds an inline map accepting any key.
+	InlineMap bool
+}
+
+var structInfoCache sync.Map // reflect.Type -> *structInfo
+
+// getStructInfo returns the cached metadata of struct type tp.
+// Assumes that tp has already been validated using ValidateType.
+func getStructInfo(tp reflect.Type) *structInfo {
+	if i, ok := structInfoCache.Load(tp); ok {
+		return i.(*structInfo)
+	}
+	info := &structInfo{Keys: map[string]struct{}{}}
+	for i := range tp.NumField() {
+		f := tp.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		yamlTag := getYAMLFieldName(f.Tag)
+		sf := structField{StructField: f, YAMLTag: yamlTag}
+		if def, ok := f.Tag.Lookup("default"); ok && yamlTag != "-" {
+			sf.Default, _ = parseDefault(def)
+		}
+		info.Fields = append(info.Fields, sf)
+		switch {
+		case yamlTag == "-":
+		case f.Anonymous:
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() != reflect.Struct {
+				info.InlineMap = true
+				continue
+			}
+			embeddedInfo := getStructInfo(embedded)
+			for k := range embeddedInfo.Keys {
+				info.Keys[k] = struct{}{}
+			}
+			info.Order = append(info.Order, embeddedInfo.Order...)
+			info.InlineMap = info.InlineMap || embeddedInfo.InlineMap
+		default:
+			info.Keys[yamlTag] = struct{}{}
+			info.Order = append(info.Order, yamlTag)
+		}
+	}
+	i, _ := structInfoCache.LoadOrStore(tp, info)
+	return i.(*structInfo)
+}
+
+// isPlainStruct returns true if tp is a struct type that implements
+// neither encoding.TextUnmarshaler nor yaml.Unmarshaler.
+func isPlainStruct(tp reflect.Type) bool {
+	return tp.Kind() == reflect.Struct &&
+		!implementsInterface[encoding.TextUnmarshaler](tp) &&
+		!implementsInterface[yaml.Unmarshaler](tp)
+}
+
 func getYAMLFieldName(t reflect.StructTag) string {
 	yamlTag := t.Get("yaml")
 	if i := strings.IndexByte(yamlTag, ','); i != -1 {
@@ -1005,7 +1912,18 @@ func yamlTagIsInline(t reflect.StructTag) bool {
 	return false
 }
 
-func validateEnvField(f reflect.StructField) error {
+func yamlTagHasOption(t reflect.StructTag, option string) bool {
+	yamlTag := t.Get("yaml")
+	opts := strings.Split(yamlTag, ",")
+	for _, opt := range opts[1:] {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+func validateEnvField(r *Rules, f reflect.StructField) error {
 	n, ok := f.Tag.Lookup("env")
 	if !ok {
 		return nil
@@ -1019,17 +1937,26 @@ func validateEnvField(f reflect.StructField) error {
 		return ErrTypeInvalidEnvTag
 	}
 
-	if implementsInterface[yaml.Unmarshaler](f.Type) {
+	tp := f.Type
+	if t, ok := secretValueType(tp); ok {
+		tp = t
+	}
+
+	if implementsInterface[yaml.Unmarshaler](tp) {
 		return fmt.Errorf("%w: %s", ErrTypeEnvOnYAMLUnmarsh, f.Type.String())
 	}
 
-	switch k := f.Type.Kind(); {
-	case kindIsPrimitive(k):
+	isPrimitive := func(k reflect.Kind) bool {
+		return kindIsPrimitive(k) ||
+			(r.AllowIntUint && (k == reflect.Int || k == reflect.Uint))
+	}
+	switch k := tp.Kind(); {
+	case isPrimitive(k):
 		return nil
-	case k == reflect.Pointer && kindIsPrimitive(f.Type.Elem().Kind()):
+	case k == reflect.Pointer && isPrimitive(tp.Elem().Kind()):
 		// Pointer to primitve
 		return nil
-	case implementsInterface[encoding.TextUnmarshaler](f.Type):
+	case implementsInterface[encoding.TextUnmarshaler](tp):
 		return nil
 	}
 	return fmt.Errorf("%w: %s", ErrTypeEnvVarOnUnsupportedType, f.Type.String())
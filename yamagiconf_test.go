@@ -1,6 +1,7 @@
 package yamagiconf_test
 
 import (
+	"context"
 	"encoding"
 	"errors"
 	"fmt"
@@ -13,6 +14,13 @@ import (
 
 	"github.com/romshark/yamagiconf"
 
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/fr"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 )
@@ -1678,7 +1686,8 @@ container:
 `)
 		require.ErrorIs(t, err, yamagiconf.ErrValidationTag)
 		require.Equal(t,
-			`at 3:17: "required-str" violates validation rule: "required"`,
+			`at 3:17: "required-str" violates validation rule: "required"`+"\n"+
+				`at TestConfig.Container.NoYAMLStr: violates validation rule: "required"`,
 			err.Error())
 		validateErr := yamagiconf.Validate(
 			TestConfig{Container: Container{Str: ""}},
@@ -1808,8 +1817,11 @@ container:
 		validateErr := yamagiconf.Validate(
 			TestConfig{Container: Container{Str: ""}},
 		)
-		require.NoError(t, CompareErrMsgWithPrefix(err, validateErr,
-			`at TestConfig.Container.NoYAMLStr:`, "at TestConfig.Container.Str:"))
+		require.ErrorIs(t, validateErr, yamagiconf.ErrValidationTag)
+		require.Equal(t,
+			`at TestConfig.Container.Str: violates validation rule: "required"`+"\n"+
+				`at TestConfig.Container.NoYAMLStr: violates validation rule: "required"`,
+			validateErr.Error())
 	})
 }
 
@@ -3081,3 +3093,687 @@ container:
 
 	require.Zero(t, c.Container)
 }
+
+func TestWithValidator(t *testing.T) {
+	newValidator := func(t *testing.T) *validator.Validate {
+		t.Helper()
+		v := validator.New(validator.WithRequiredStructEnabled())
+		err := v.RegisterValidation("even", func(fl validator.FieldLevel) bool {
+			return fl.Field().Int()%2 == 0
+		})
+		require.NoError(t, err)
+		v.RegisterAlias("port", "required,even")
+		return v
+	}
+
+	type Server struct {
+		Port int32 `yaml:"port" validate:"port"`
+	}
+	type Container struct {
+		Ptr     *Server           `yaml:"ptr"`
+		Servers []Server          `yaml:"servers" validate:"dive"`
+		Map     map[string]Server `yaml:"map" validate:"dive"`
+		Evens   []int64           `yaml:"evens" validate:"dive,even"`
+	}
+	type TestConfig struct {
+		Container Container `yaml:"container"`
+	}
+
+	const src = `container:
+  ptr:
+    port: %d
+  servers:
+    - port: 2
+    - port: %d
+  map:
+    foo.bar:
+      port: %d
+  evens: [2, %d]
+`
+
+	for _, td := range []struct {
+		name        string
+		ptr, server int32
+		mapVal      int32
+		even        int64
+		expectErr   string
+		expectPath  string
+	}{
+		{name: "ok", ptr: 2, server: 4, mapVal: 6, even: 8},
+		{
+			name: "err_ptr", ptr: 3, server: 4, mapVal: 6, even: 8,
+			expectErr:  `at 3:11: "port" violates validation rule: "port", got 3`,
+			expectPath: `at TestConfig.Container.Ptr.Port:`,
+		},
+		{
+			name: "err_slice", ptr: 2, server: 5, mapVal: 6, even: 8,
+			expectErr:  `at 6:13: "port" violates validation rule: "port", got 5`,
+			expectPath: `at TestConfig.Container.Servers[1].Port:`,
+		},
+		{
+			name: "err_map", ptr: 2, server: 4, mapVal: 7, even: 8,
+			expectErr:  `at 9:13: "port" violates validation rule: "port", got 7`,
+			expectPath: `at TestConfig.Container.Map[foo.bar].Port:`,
+		},
+		{
+			name: "err_dive", ptr: 2, server: 4, mapVal: 6, even: 9,
+			expectErr:  `at 10:14: "evens" violates validation rule: "even", got 9`,
+			expectPath: `at TestConfig.Container.Evens[1]:`,
+		},
+	} {
+		t.Run(td.name, func(t *testing.T) {
+			v := newValidator(t)
+			var c TestConfig
+			err := yamagiconf.Load(
+				fmt.Sprintf(src, td.ptr, td.server, td.mapVal, td.even), &c,
+				yamagiconf.WithValidator(v),
+			)
+			validateErr := yamagiconf.Validate(c, yamagiconf.WithValidator(v))
+			if td.expectErr == "" {
+				require.NoError(t, err)
+				require.NoError(t, validateErr)
+				return
+			}
+			require.ErrorIs(t, err, yamagiconf.ErrValidationTag)
+			require.Equal(t, td.expectErr, err.Error())
+			require.ErrorIs(t, validateErr, yamagiconf.ErrValidationTag)
+			require.True(t, strings.HasPrefix(validateErr.Error(), td.expectPath))
+		})
+	}
+
+	t.Run("custom_tag_name", func(t *testing.T) {
+		type TestConfig struct {
+			Name string `yaml:"name" check:"required"`
+		}
+		v := validator.New(validator.WithRequiredStructEnabled())
+		v.SetTagName("check")
+		var c TestConfig
+		err := yamagiconf.Load("name: ''", &c, yamagiconf.WithValidator(v))
+		require.ErrorIs(t, err, yamagiconf.ErrValidationTag)
+		require.Equal(t,
+			`at 1:7: "name" violates validation rule: "required"`, err.Error())
+	})
+
+	t.Run("struct_validation", func(t *testing.T) {
+		type TLS struct {
+			Enabled bool   `yaml:"enabled"`
+			Cert    string `yaml:"cert"`
+		}
+		type TestConfig struct {
+			TLS TLS `yaml:"tls"`
+		}
+		v := validator.New(validator.WithRequiredStructEnabled())
+		v.RegisterStructValidation(func(sl validator.StructLevel) {
+			tls := sl.Current().Interface().(TLS)
+			if tls.Enabled && tls.Cert == "" {
+				sl.ReportError(tls.Cert, "Cert", "Cert", "cert_required", "")
+			}
+		}, TLS{})
+		var c TestConfig
+		err := yamagiconf.Load(
+			"tls:\n  enabled: true\n  cert: ''", &c, yamagiconf.WithValidator(v),
+		)
+		require.ErrorIs(t, err, yamagiconf.ErrValidationTag)
+		require.Equal(t,
+			`at 3:9: "cert" violates validation rule: "cert_required"`, err.Error())
+	})
+
+	t.Run("loader", func(t *testing.T) {
+		l, err := yamagiconf.NewLoader[TestConfig](
+			yamagiconf.WithValidator(newValidator(t)),
+		)
+		require.NoError(t, err)
+		var c TestConfig
+		err = l.Load([]byte(fmt.Sprintf(src, 2, 4, 6, 8)), &c)
+		require.NoError(t, err)
+		err = l.Load([]byte(fmt.Sprintf(src, 2, 4, 6, 1)), &c)
+		require.ErrorIs(t, err, yamagiconf.ErrValidationTag)
+	})
+}
+
+func TestValidateTypeErrInvalidValidateTag(t *testing.T) {
+	type Server struct {
+		Host string `yaml:"host" validate:"requird"`
+	}
+	type TestConfig struct {
+		Servers []Server `yaml:"servers"`
+	}
+
+	t.Run("nested", func(t *testing.T) {
+		err := yamagiconf.ValidateType[TestConfig]()
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidValidateTag)
+		require.Equal(t, "at TestConfig.Servers.Host: "+
+			"invalid validate struct tag: "+
+			"Undefined validation function 'requird' on field 'Host'", err.Error())
+	})
+
+	t.Run("load", func(t *testing.T) {
+		var c TestConfig
+		err := yamagiconf.Load("servers: []", &c)
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidValidateTag)
+	})
+
+	t.Run("dive", func(t *testing.T) {
+		type TestConfig struct {
+			Hosts []string `yaml:"hosts" validate:"dive,hostnam"`
+		}
+		err := yamagiconf.ValidateType[TestConfig]()
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidValidateTag)
+		require.True(t, strings.HasPrefix(err.Error(), "at TestConfig.Hosts: "))
+	})
+
+	t.Run("custom_validation", func(t *testing.T) {
+		type TestConfig struct {
+			Port  int32 `yaml:"port" validate:"even"`
+			Alias int32 `yaml:"alias" validate:"port"`
+		}
+		err := yamagiconf.ValidateType[TestConfig]()
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidValidateTag)
+		require.Equal(t, "at TestConfig.Port: invalid validate struct tag: "+
+			"Undefined validation function 'even' on field 'Port'", err.Error())
+
+		v := validator.New(validator.WithRequiredStructEnabled())
+		require.NoError(t, v.RegisterValidation("even", func(fl validator.FieldLevel) bool {
+			return fl.Field().Int()%2 == 0
+		}))
+		err = yamagiconf.ValidateType[TestConfig](yamagiconf.WithValidator(v))
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidValidateTag)
+		require.Equal(t, "at TestConfig.Alias: invalid validate struct tag: "+
+			"Undefined validation function 'port' on field 'Alias'", err.Error())
+
+		v.RegisterAlias("port", "required,even")
+		require.NoError(t, yamagiconf.ValidateType[TestConfig](yamagiconf.WithValidator(v)))
+	})
+
+	t.Run("custom_tag_name", func(t *testing.T) {
+		type TestConfig struct {
+			Name string `yaml:"name" validate:"requird" check:"required"`
+		}
+		v := validator.New(validator.WithRequiredStructEnabled())
+		v.SetTagName("check")
+		require.NoError(t, yamagiconf.ValidateType[TestConfig](yamagiconf.WithValidator(v)))
+	})
+
+	t.Run("field_validation_not_called", func(t *testing.T) {
+		type TestConfig struct {
+			Name string `yaml:"name" validate:"explode"`
+		}
+		v := validator.New(validator.WithRequiredStructEnabled())
+		require.NoError(t, v.RegisterValidation("explode", func(validator.FieldLevel) bool {
+			panic("explode called")
+		}))
+		require.NoError(t, yamagiconf.ValidateType[TestConfig](yamagiconf.WithValidator(v)))
+	})
+
+	t.Run("struct_validation_not_called", func(t *testing.T) {
+		type TestConfig struct {
+			Name string `yaml:"name" validate:"required"`
+		}
+		v := validator.New(validator.WithRequiredStructEnabled())
+		called := false
+		v.RegisterStructValidation(func(validator.StructLevel) {
+			called = true
+		}, TestConfig{})
+		require.NoError(t, yamagiconf.ValidateType[TestConfig](yamagiconf.WithValidator(v)))
+		require.False(t, called)
+	})
+
+	t.Run("ignored_field", func(t *testing.T) {
+		type Internal struct {
+			ID string `validate:"requird"`
+		}
+		type TestConfig struct {
+			Name     string      `yaml:"name"`
+			Internal []*Internal `yaml:"-"`
+		}
+		err := yamagiconf.ValidateType[TestConfig]()
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidValidateTag)
+		require.Equal(t, "at TestConfig.Internal.ID: invalid validate struct tag: "+
+			"Undefined validation function 'requird' on field 'ID'", err.Error())
+	})
+
+	t.Run("embedded", func(t *testing.T) {
+		type Base struct {
+			Host string `yaml:"host"`
+		}
+		type TestConfig struct {
+			Base `yaml:",inline" validate:"requird"`
+		}
+		err := yamagiconf.ValidateType[TestConfig]()
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidValidateTag)
+		require.Equal(t, "at TestConfig.Base: invalid validate struct tag: "+
+			"Undefined validation function 'requird' on field 'Base'", err.Error())
+	})
+
+	t.Run("misplaced_dive", func(t *testing.T) {
+		type TestConfig struct {
+			Name string `yaml:"name" validate:"dive,required"`
+		}
+		err := yamagiconf.ValidateType[TestConfig]()
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidValidateTag)
+		require.Equal(t, "at TestConfig.Name: invalid validate struct tag: "+
+			"'dive' can't be used on string, only on slices, arrays and maps",
+			err.Error())
+
+		var c TestConfig
+		err = yamagiconf.Load("name: x", &c)
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidValidateTag)
+	})
+
+	t.Run("misplaced_dive_nested", func(t *testing.T) {
+		type TestConfig struct {
+			Names [][]*string `yaml:"names" validate:"dive,dive,dive"`
+		}
+		err := yamagiconf.ValidateType[TestConfig]()
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidValidateTag)
+		require.Equal(t, "at TestConfig.Names: invalid validate struct tag: "+
+			"'dive' can't be used on string, only on slices, arrays and maps",
+			err.Error())
+	})
+
+	t.Run("misplaced_keys", func(t *testing.T) {
+		type TestConfig struct {
+			Names []string `yaml:"names" validate:"dive,keys,min=1,endkeys"`
+		}
+		err := yamagiconf.ValidateType[TestConfig]()
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidValidateTag)
+		require.Equal(t, "at TestConfig.Names: invalid validate struct tag: "+
+			"'keys' can't be used on []string, only on maps", err.Error())
+	})
+
+	t.Run("misplaced_dive_in_keys", func(t *testing.T) {
+		type TestConfig struct {
+			Labels map[string][]string `yaml:"labels" validate:"dive,keys,dive,endkeys,dive,min=1"`
+		}
+		err := yamagiconf.ValidateType[TestConfig]()
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidValidateTag)
+		require.Equal(t, "at TestConfig.Labels: invalid validate struct tag: "+
+			"'dive' can't be used on string, only on slices, arrays and maps",
+			err.Error())
+	})
+
+	t.Run("valid_dive", func(t *testing.T) {
+		type TestConfig struct {
+			Hosts  [2]*string          `yaml:"hosts" validate:"dive,omitempty,min=1"`
+			Labels map[string][]string `yaml:"labels" validate:"dive,keys,min=1,endkeys,dive,min=1"`
+			Keys   map[string]string   `yaml:"keys" validate:"dive,keys,min=1,endkeys"`
+		}
+		require.NoError(t, yamagiconf.ValidateType[TestConfig]())
+	})
+
+	t.Run("misplaced_dive_custom_tag_name", func(t *testing.T) {
+		type TestConfig struct {
+			Name string `yaml:"name" validate:"required" check:"dive"`
+		}
+		v := validator.New(validator.WithRequiredStructEnabled())
+		v.SetTagName("check")
+		err := yamagiconf.ValidateType[TestConfig](yamagiconf.WithValidator(v))
+		require.ErrorIs(t, err, yamagiconf.ErrTypeInvalidValidateTag)
+	})
+}
+
+func TestValidationTagErrorsAll(t *testing.T) {
+	type Server struct {
+		Host string `yaml:"host" validate:"required,hostname"`
+		Port uint32 `yaml:"port" validate:"max=65535"`
+		Mode string `yaml:"mode" validate:"oneof=dev prod"`
+	}
+	type TestConfig struct {
+		Server Server `yaml:"server"`
+	}
+
+	var c TestConfig
+	err := yamagiconf.Load(`server:
+  host: 'not a hostname'
+  port: 70000
+  mode: ''
+`, &c)
+	require.ErrorIs(t, err, yamagiconf.ErrValidationTag)
+	require.Equal(t,
+		`at 2:9: "host" violates validation rule: "hostname", got "not a hostname"`+"\n"+
+			`at 3:9: "port" violates validation rule: "max=65535", got 70000`+"\n"+
+			`at 4:9: "mode" violates validation rule: "oneof=dev prod", got ""`,
+		err.Error())
+
+	validateErr := yamagiconf.Validate(c)
+	require.ErrorIs(t, validateErr, yamagiconf.ErrValidationTag)
+	require.Equal(t,
+		`at TestConfig.Server.Host: violates validation rule: "hostname", `+
+			`got "not a hostname"`+"\n"+
+			`at TestConfig.Server.Port: violates validation rule: "max=65535", `+
+			`got 70000`+"\n"+
+			`at TestConfig.Server.Mode: violates validation rule: "oneof=dev prod", `+
+			`got ""`,
+		validateErr.Error())
+}
+
+func TestWithTranslator(t *testing.T) {
+	type TestConfig struct {
+		Port uint32 `yaml:"port" validate:"max=65535"`
+	}
+
+	newValidator := func(
+		t *testing.T, l locales.Translator,
+		register func(*validator.Validate, ut.Translator) error,
+	) (*validator.Validate, ut.Translator) {
+		t.Helper()
+		trans, ok := ut.New(l, l).GetTranslator(l.Locale())
+		require.True(t, ok)
+		v := validator.New(validator.WithRequiredStructEnabled())
+		require.NoError(t, register(v, trans))
+		return v, trans
+	}
+
+	t.Run("en", func(t *testing.T) {
+		v, trans := newValidator(t, en.New(), en_translations.RegisterDefaultTranslations)
+		var c TestConfig
+		err := yamagiconf.Load("port: 70000", &c,
+			yamagiconf.WithValidator(v), yamagiconf.WithTranslator(trans))
+		require.ErrorIs(t, err, yamagiconf.ErrValidationTag)
+		require.Equal(t, `at 1:7: "port" violates validation rule: "max=65535", `+
+			`got 70000: Port must be 65,535 or less`, err.Error())
+	})
+
+	t.Run("fr", func(t *testing.T) {
+		v, trans := newValidator(t, fr.New(), fr_translations.RegisterDefaultTranslations)
+		err := yamagiconf.Validate(TestConfig{Port: 70000},
+			yamagiconf.WithValidator(v), yamagiconf.WithTranslator(trans))
+		require.ErrorIs(t, err, yamagiconf.ErrValidationTag)
+		require.Equal(t, `at TestConfig.Port: violates validation rule: "max=65535", `+
+			"got 70000: Port doit être égal à 65\u202f535 ou moins", err.Error())
+	})
+
+	t.Run("without_validator", func(t *testing.T) {
+		_, trans := newValidator(t, en.New(), en_translations.RegisterDefaultTranslations)
+		var c TestConfig
+		err := yamagiconf.Load("port: 70000", &c, yamagiconf.WithTranslator(trans))
+		require.ErrorIs(t, err, yamagiconf.ErrTranslatorWithoutValidator)
+
+		_, err = yamagiconf.NewLoader[TestConfig](yamagiconf.WithTranslator(trans))
+		require.ErrorIs(t, err, yamagiconf.ErrTranslatorWithoutValidator)
+	})
+}
+
+type FieldErrTLS struct {
+	Enabled bool   `yaml:"enabled"`
+	Cert    string `yaml:"cert"`
+}
+
+func (t FieldErrTLS) Validate() error {
+	if t.Enabled && t.Cert == "" {
+		return yamagiconf.FieldErr("Cert",
+			errors.New("cert is required when enabled is true"))
+	}
+	return nil
+}
+
+type FieldErrServer struct {
+	Host string      `yaml:"host"`
+	TLS  FieldErrTLS `yaml:"tls"`
+}
+
+type FieldErrConfig struct {
+	Servers []FieldErrServer     `yaml:"servers"`
+	Labels  map[string]string    `yaml:"labels"`
+	Backup  *FieldErrServer      `yaml:"backup"`
+	Hosts   map[string][2]string `yaml:"hosts"`
+}
+
+var ErrFieldErrTest = errors.New("test error")
+
+func (c *FieldErrConfig) Validate() error {
+	for i, s := range c.Servers {
+		if s.Host == "forbidden" {
+			return yamagiconf.FieldErr(fmt.Sprintf("Servers[%d].Host", i),
+				fmt.Errorf("host %q: %w", s.Host, ErrFieldErrTest))
+		}
+	}
+	if v, ok := c.Labels["env"]; ok && v != "prod" {
+		return yamagiconf.FieldErr("Labels[env]", errors.New("must be prod"))
+	}
+	if c.Backup != nil && c.Backup.Host == "" {
+		return yamagiconf.FieldErr("Backup.Host", errors.New("must not be empty"))
+	}
+	if h, ok := c.Hosts["a.b"]; ok && h[1] == "" {
+		return yamagiconf.FieldErr("Hosts[a.b][1]", errors.New("must not be empty"))
+	}
+	return nil
+}
+
+func TestFieldErr(t *testing.T) {
+	const src = `servers:
+  - host: first
+    tls:
+      enabled: false
+      cert: ''
+  - host: %s
+    tls:
+      enabled: %t
+      cert: ''
+labels:
+  env: %s
+backup:
+  host: %s
+  tls:
+    enabled: false
+    cert: ''
+hosts:
+  a.b: [x, %q]
+`
+	for _, td := range []struct {
+		name          string
+		host          string
+		tlsEnabled    bool
+		env, backup   string
+		hostsItem     string
+		expect        string
+		expectNoYAML  string
+		expectErrorIs error
+	}{
+		{
+			name: "ok", host: "second", env: "prod", backup: "x", hostsItem: "y",
+		},
+		{
+			name: "nested_struct", host: "second", tlsEnabled: true,
+			env: "prod", backup: "x", hostsItem: "y",
+			expect: `at 9:13: at FieldErrConfig.Servers[1].TLS.Cert: ` +
+				`validation: cert is required when enabled is true`,
+			expectNoYAML: `at FieldErrConfig.Servers[1].TLS.Cert: ` +
+				`validation: cert is required when enabled is true`,
+		},
+		{
+			name: "slice_index", host: "forbidden", env: "prod", backup: "x", hostsItem: "y",
+			expect: `at 6:11: at FieldErrConfig.Servers[1].Host: ` +
+				`validation: host "forbidden": test error`,
+			expectNoYAML: `at FieldErrConfig.Servers[1].Host: ` +
+				`validation: host "forbidden": test error`,
+			expectErrorIs: ErrFieldErrTest,
+		},
+		{
+			name: "map_key", host: "second", env: "dev", backup: "x", hostsItem: "y",
+			expect: `at 11:8: at FieldErrConfig.Labels[env]: ` +
+				`validation: must be prod`,
+			expectNoYAML: `at FieldErrConfig.Labels[env]: validation: must be prod`,
+		},
+		{
+			name: "pointer", host: "second", env: "prod", backup: "''", hostsItem: "y",
+			expect: `at 13:9: at FieldErrConfig.Backup.Host: ` +
+				`validation: must not be empty`,
+			expectNoYAML: `at FieldErrConfig.Backup.Host: validation: must not be empty`,
+		},
+		{
+			name: "map_key_with_dot_and_array", host: "second", env: "prod",
+			backup: "x", hostsItem: "",
+			expect: `at 18:12: at FieldErrConfig.Hosts[a.b][1]: ` +
+				`validation: must not be empty`,
+			expectNoYAML: `at FieldErrConfig.Hosts[a.b][1]: ` +
+				`validation: must not be empty`,
+		},
+	} {
+		t.Run(td.name, func(t *testing.T) {
+			c, err := LoadSrc[FieldErrConfig](fmt.Sprintf(src,
+				td.host, td.tlsEnabled, td.env, td.backup, td.hostsItem))
+			validateErr := yamagiconf.Validate(*c)
+			if td.expect == "" {
+				require.NoError(t, err)
+				require.NoError(t, validateErr)
+				return
+			}
+			require.ErrorIs(t, err, yamagiconf.ErrValidation)
+			require.Equal(t, td.expect, err.Error())
+			require.ErrorIs(t, validateErr, yamagiconf.ErrValidation)
+			require.Equal(t, td.expectNoYAML, validateErr.Error())
+			if td.expectErrorIs != nil {
+				require.ErrorIs(t, err, td.expectErrorIs)
+			}
+		})
+	}
+}
+
+type CtxRoute struct {
+	Upstream string `yaml:"upstream"`
+}
+
+func (r CtxRoute) Validate(ctx context.Context, root any) error {
+	c := root.(*CtxConfig)
+	if _, ok := c.Upstreams[r.Upstream]; !ok {
+		return yamagiconf.FieldErr("Upstream",
+			fmt.Errorf("upstream %q not defined", r.Upstream))
+	}
+	return nil
+}
+
+type CtxCAFile string
+
+var ErrCtxTestTimeout = errors.New("test timeout")
+
+func (f CtxCAFile) Validate(ctx context.Context, root any) error {
+	if f != "slow" {
+		return nil
+	}
+	<-ctx.Done()
+	return fmt.Errorf("%w: %w", ErrCtxTestTimeout, ctx.Err())
+}
+
+type CtxConfig struct {
+	Upstreams map[string]string `yaml:"upstreams"`
+	Routes    []CtxRoute        `yaml:"routes"`
+	CAFile    CtxCAFile         `yaml:"ca-file"`
+}
+
+var (
+	_ yamagiconf.ValidatorContext = CtxRoute{}
+	_ yamagiconf.ValidatorContext = CtxCAFile("")
+)
+
+func TestValidatorContext(t *testing.T) {
+	const src = `upstreams:
+  api: http://api.local
+routes:
+  - upstream: api
+  - upstream: %s
+ca-file: %s
+`
+
+	t.Run("ok", func(t *testing.T) {
+		var c CtxConfig
+		err := yamagiconf.LoadContext(
+			context.Background(), fmt.Sprintf(src, "api", "ca.pem"), &c,
+		)
+		require.NoError(t, err)
+		require.NoError(t, yamagiconf.Validate(c))
+	})
+
+	t.Run("err_root_reference", func(t *testing.T) {
+		var c CtxConfig
+		err := yamagiconf.Load(fmt.Sprintf(src, "web", "ca.pem"), &c)
+		require.ErrorIs(t, err, yamagiconf.ErrValidation)
+		require.Equal(t, `at 5:15: at CtxConfig.Routes[1].Upstream: `+
+			`validation: upstream "web" not defined`, err.Error())
+
+		err = yamagiconf.Validate(c)
+		require.ErrorIs(t, err, yamagiconf.ErrValidation)
+		require.Equal(t, `at CtxConfig.Routes[1].Upstream: `+
+			`validation: upstream "web" not defined`, err.Error())
+	})
+
+	t.Run("err_deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		p := filepath.Join(t.TempDir(), "test-config.yaml")
+		err := os.WriteFile(p, []byte(fmt.Sprintf(src, "api", "slow")), 0o664)
+		require.NoError(t, err)
+		var c CtxConfig
+		err = yamagiconf.LoadFileContext(ctx, p, &c)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorIs(t, err, ErrCtxTestTimeout)
+		require.Equal(t, `at CtxConfig.CAFile: test timeout: `+
+			`context deadline exceeded`, err.Error())
+	})
+
+	t.Run("err_canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		l, err := yamagiconf.NewLoader[CtxConfig]()
+		require.NoError(t, err)
+		var c CtxConfig
+		err = l.LoadContext(ctx, []byte(fmt.Sprintf(src, "api", "ca.pem")), &c)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestLoadQuotedNullVariants(t *testing.T) {
+	type TestConfig struct {
+		Tilde     string  `yaml:"tilde"`
+		Upper     string  `yaml:"upper"`
+		PtrTilde  *string `yaml:"ptr-tilde"`
+		PtrQuoted *string `yaml:"ptr-quoted"`
+	}
+	c, err := LoadSrc[TestConfig](`tilde: "~"
+upper: 'NULL'
+ptr-tilde: '~'
+ptr-quoted: "null"
+`)
+	require.NoError(t, err)
+	require.Equal(t, "~", c.Tilde)
+	require.Equal(t, "NULL", c.Upper)
+	require.Equal(t, "~", *c.PtrTilde)
+	require.Equal(t, "null", *c.PtrQuoted)
+
+	_, err = LoadSrc[TestConfig](`tilde: "~"
+upper: 'NULL'
+ptr-tilde: ~
+ptr-quoted: "null"
+`)
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLBadNullLiteral)
+
+	// Unquoted null variants stay forbidden for strings.
+	for _, v := range []string{"~", "NULL", "Null", "nUll"} {
+		_, err = LoadSrc[TestConfig]("tilde: " + v + "\nupper: x\n" +
+			"ptr-tilde: null\nptr-quoted: null\n")
+		require.ErrorIs(t, err, yamagiconf.ErrYAMLBadNullLiteral, v)
+	}
+}
+
+func TestLoadPointerToStructValues(t *testing.T) {
+	type Inner struct {
+		Flag bool   `yaml:"flag"`
+		Name string `yaml:"name"`
+	}
+	type TestConfig struct {
+		Inner *Inner `yaml:"inner"`
+	}
+
+	_, err := LoadSrc[TestConfig]("inner:\n  flag: yes\n  name: x\n")
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLBadBoolLiteral)
+	require.Equal(t, `at 2:9: "flag" (TestConfig.Inner.Flag): must be either `+
+		`false or true, other variants of boolean literals `+
+		`of YAML are not supported`, err.Error())
+
+	_, err = LoadSrc[TestConfig]("inner:\n  flag: true\n")
+	require.ErrorIs(t, err, yamagiconf.ErrYAMLMissingConfig)
+
+	c, err := LoadSrc[TestConfig]("inner: null\n")
+	require.NoError(t, err)
+	require.Nil(t, c.Inner)
+}